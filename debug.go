@@ -0,0 +1,31 @@
+//go:build immutable_debug
+
+package immutable
+
+// SharesStructure returns true if a and b share at least one internal node,
+// which is the case when one map was derived from the other by a small number
+// of edits. It is intended for tests that check memory assumptions and is only
+// available when built with the immutable_debug tag.
+func SharesStructure[K comparable, V any](a, b *Map[K, V]) bool {
+	return sharedMapNodes(a, b) > 0
+}
+
+// sharedMapNodes returns the number of nodes in b that are also in a.
+func sharedMapNodes[K comparable, V any](a, b *Map[K, V]) int {
+	if a == nil || b == nil {
+		return 0
+	}
+
+	nodes := make(map[mapNode[K, V]]struct{})
+	a.walk(func(n mapNode[K, V], depth int) {
+		nodes[n] = struct{}{}
+	})
+
+	var shared int
+	b.walk(func(n mapNode[K, V], depth int) {
+		if _, ok := nodes[n]; ok {
+			shared++
+		}
+	})
+	return shared
+}
@@ -0,0 +1,38 @@
+//go:build immutable_debug
+
+package immutable
+
+import (
+	"testing"
+)
+
+func TestSharesStructure(t *testing.T) {
+	const n = 100000
+	b := NewMapBuilder[int, int](nil)
+	for i := 0; i < n; i++ {
+		b.Set(i, i)
+	}
+	m := b.Map()
+
+	// A single Set only copies the path to the key so the bulk of the tree is
+	// shared.
+	other := m.Set(n/2, -1)
+	if !SharesStructure(m, other) {
+		t.Fatal("expected derived map to share structure")
+	}
+	total := other.Stats().Nodes
+	if shared := sharedMapNodes(m, other); total-shared > other.Stats().Depth {
+		t.Fatalf("shared %d of %d nodes, expected all but the path to the key", shared, total)
+	}
+
+	// Maps built independently share nothing.
+	b = NewMapBuilder[int, int](nil)
+	for i := 0; i < n; i++ {
+		b.Set(i, i)
+	}
+	if SharesStructure(m, b.Map()) {
+		t.Fatal("expected independently built maps to share no structure")
+	} else if SharesStructure(m, NewMap[int, int](nil)) || SharesStructure(nil, m) {
+		t.Fatal("expected empty maps to share no structure")
+	}
+}
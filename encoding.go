@@ -0,0 +1,249 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MarshalJSON implements json.Marshaler. The list is encoded as a JSON array
+// with each element encoded using encoding/json. An empty list is encoded as [].
+func (l *List[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	itr := l.Iterator()
+	for !itr.Done() {
+		i, v := itr.Next()
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeJSON(&buf, v); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It decodes a JSON array and
+// replaces the contents of the list with its elements.
+func (l *List[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	b := NewListBuilder[T]()
+	for _, v := range values {
+		b.Append(v)
+	}
+	*l = *b.List()
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Maps with string keys are encoded as
+// a JSON object. Maps with keys that implement encoding.TextMarshaler, and
+// whose pointer type implements encoding.TextUnmarshaler, are also encoded as
+// a JSON object using the marshaled text of each key. An error is returned if
+// two keys marshal to the same text. Maps with any other key type are encoded
+// as an array of [key, value] pairs. Keys and values are encoded using
+// encoding/json.
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	textKeys := !isStringKind[K]() && isTextKey[K]()
+	stringKeys := isStringKind[K]() || textKeys
+
+	var seen map[string]struct{}
+	if textKeys {
+		seen = make(map[string]struct{}, m.Len())
+	}
+
+	var buf bytes.Buffer
+	if stringKeys {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+
+	itr := m.Iterator()
+	for i := 0; !itr.Done(); i++ {
+		k, v, _ := itr.Next()
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if !stringKeys {
+			buf.WriteByte('[')
+		}
+
+		// Named string types are encoded by their underlying string so that any
+		// custom marshaler on the key type does not produce a non-string key.
+		var key any = k
+		if textKeys {
+			text, err := any(k).(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			} else if _, ok := seen[string(text)]; ok {
+				return nil, fmt.Errorf("immutable.Map.MarshalJSON: duplicate key text %q", text)
+			}
+			seen[string(text)] = struct{}{}
+			key = string(text)
+		} else if stringKeys {
+			key = reflect.ValueOf(k).String()
+		}
+		if err := writeJSON(&buf, key); err != nil {
+			return nil, err
+		}
+
+		if stringKeys {
+			buf.WriteByte(':')
+		} else {
+			buf.WriteByte(',')
+		}
+		if err := writeJSON(&buf, v); err != nil {
+			return nil, err
+		}
+
+		if !stringKeys {
+			buf.WriteByte(']')
+		}
+	}
+
+	if stringKeys {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts the same formats that
+// are produced by MarshalJSON and replaces the contents of the map.
+//
+// Decoded keys are hashed with the map's existing hasher, if one is set.
+// Otherwise a default hasher is chosen based on the key type which will panic
+// if no default hasher exists for the type. To decode keys which require a
+// custom hasher, decode into a map created with NewMap(hasher).
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	b := NewMapBuilder[K, V](m.hasher)
+
+	if !isStringKind[K]() && isTextKey[K]() {
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		for text, raw := range entries {
+			var k K
+			var v V
+			if err := any(&k).(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+				return err
+			} else if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			b.Set(k, v)
+		}
+	} else if isStringKind[K]() {
+		var entries map[K]V
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		for k, v := range entries {
+			b.Set(k, v)
+		}
+	} else {
+		var pairs [][2]json.RawMessage
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			var k K
+			var v V
+			if err := json.Unmarshal(pair[0], &k); err != nil {
+				return err
+			} else if err := json.Unmarshal(pair[1], &v); err != nil {
+				return err
+			}
+			b.Set(k, v)
+		}
+	}
+
+	*m = *b.Map()
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder. Key/value pairs are encoded in
+// comparer order. The comparer itself is not encoded.
+func (m *SortedMap[K, V]) GobEncode() ([]byte, error) {
+	enc := sortedMapGob[K, V]{
+		Keys:   make([]K, 0, m.Len()),
+		Values: make([]V, 0, m.Len()),
+	}
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		enc.Keys = append(enc.Keys, k)
+		enc.Values = append(enc.Values, v)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(enc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder and replaces the contents of the map
+// with the decoded key/value pairs.
+//
+// Comparers cannot be encoded so keys are sorted with the map's existing
+// comparer, if one is set. Otherwise a default comparer is chosen based on the
+// key type which will panic if no default comparer exists for the type. To
+// decode keys which require a custom comparer, decode into a map created
+// with NewSortedMap(comparer).
+func (m *SortedMap[K, V]) GobDecode(data []byte) error {
+	var dec sortedMapGob[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dec); err != nil {
+		return err
+	}
+
+	if len(dec.Keys) != len(dec.Values) {
+		return fmt.Errorf("immutable.SortedMap.GobDecode: %d keys but %d values", len(dec.Keys), len(dec.Values))
+	}
+
+	b := NewSortedMapBuilder[K, V](m.comparer)
+	for i := range dec.Keys {
+		b.Set(dec.Keys[i], dec.Values[i])
+	}
+	*m = *b.Map()
+	return nil
+}
+
+// sortedMapGob is the gob encoded representation of a SortedMap.
+type sortedMapGob[K comparable, V any] struct {
+	Keys   []K
+	Values []V
+}
+
+// writeJSON encodes v as JSON and appends it to buf.
+func writeJSON(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// isTextKey returns true if T implements encoding.TextMarshaler and *T
+// implements encoding.TextUnmarshaler so that keys can round trip as text.
+func isTextKey[T any]() bool {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return typ.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) &&
+		reflect.PointerTo(typ).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// isStringKind returns true if the underlying type of T is a string.
+func isStringKind[T any]() bool {
+	return reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.String
+}
@@ -0,0 +1,371 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestList_MarshalJSON(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		if buf, err := json.Marshal(NewList[int]()); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `[]` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		if buf, err := json.Marshal(NewList(1, 2, 3)); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `[1,2,3]` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+	})
+}
+
+func TestList_UnmarshalJSON(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	t.Run("Structs", func(t *testing.T) {
+		l := NewList[item]()
+		for i := 0; i < 100; i++ {
+			l = l.Append(item{Name: fmt.Sprint(i), Count: i})
+		}
+
+		buf, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other List[item]
+		if err := json.Unmarshal(buf, &other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != l.Len() {
+			t.Fatalf("Len()=%d, expected %d", other.Len(), l.Len())
+		}
+		for i := 0; i < l.Len(); i++ {
+			if v := other.Get(i); v != l.Get(i) {
+				t.Fatalf("Get(%d)=%#v, expected %#v", i, v, l.Get(i))
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		l := NewList(1, 2)
+		if err := json.Unmarshal([]byte(`[]`), l); err != nil {
+			t.Fatal(err)
+		} else if l.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", l.Len())
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		if err := json.Unmarshal([]byte(`{}`), NewList[int]()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestMap_MarshalJSON(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		if buf, err := json.Marshal(NewMap[string, int](nil)); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `{}` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+		if buf, err := json.Marshal(NewMap[int, int](nil)); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `[]` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+	})
+
+	t.Run("StringKeys", func(t *testing.T) {
+		m := NewMap[string, int](nil).Set("foo", 1)
+		if buf, err := json.Marshal(m); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `{"foo":1}` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+	})
+
+	t.Run("IntKeys", func(t *testing.T) {
+		m := NewMap[int, string](nil).Set(1, "foo")
+		if buf, err := json.Marshal(m); err != nil {
+			t.Fatal(err)
+		} else if string(buf) != `[[1,"foo"]]` {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+	})
+}
+
+func TestMap_JSONTextKeys(t *testing.T) {
+	t.Run("UUID", func(t *testing.T) {
+		a := testUUID{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+		b := testUUID{15: 1}
+		m := NewMap[testUUID, int](&testUUIDHasher{}).Set(a, 1).Set(b, 2)
+
+		buf, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var obj map[string]int
+		if err := json.Unmarshal(buf, &obj); err != nil {
+			t.Fatalf("expected JSON object: %s", buf)
+		} else if len(obj) != 2 || obj["12345678-9abc-def0-1234-56789abcdef0"] != 1 || obj["00000000-0000-0000-0000-000000000001"] != 2 {
+			t.Fatalf("unexpected JSON: %s", buf)
+		}
+
+		other := NewMap[testUUID, int](&testUUIDHasher{})
+		if err := json.Unmarshal(buf, other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", other.Len())
+		} else if v, ok := other.Get(a); !ok || v != 1 {
+			t.Fatalf("Get(a)=<%v,%v>", v, ok)
+		} else if v, ok := other.Get(b); !ok || v != 2 {
+			t.Fatalf("Get(b)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("ErrDuplicateText", func(t *testing.T) {
+		h := &mockHasher[testParityKey]{
+			hash:  func(value testParityKey) uint32 { return uint32(value) },
+			equal: func(a, b testParityKey) bool { return a == b },
+		}
+		m := NewMap[testParityKey, int](h).Set(1, 1).Set(3, 3)
+		if _, err := json.Marshal(m); err == nil || !strings.Contains(err.Error(), `duplicate key text "odd"`) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrUnmarshalText", func(t *testing.T) {
+		m := NewMap[testUUID, int](&testUUIDHasher{})
+		if err := json.Unmarshal([]byte(`{"foo":1}`), m); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+// testUUID is a key type which is encoded as text in the standard UUID format.
+type testUUID [16]byte
+
+func (u testUUID) MarshalText() ([]byte, error) {
+	s := hex.EncodeToString(u[:])
+	return []byte(s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]), nil
+}
+
+func (u *testUUID) UnmarshalText(text []byte) error {
+	if len(text) != 36 {
+		return fmt.Errorf("invalid uuid: %q", text)
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(string(text), "-", ""))
+	if err != nil {
+		return err
+	}
+	copy(u[:], b)
+	return nil
+}
+
+// testUUIDHasher implements Hasher for testUUID keys.
+type testUUIDHasher struct{}
+
+func (h *testUUIDHasher) Hash(key testUUID) uint32 {
+	return hashString(string(key[:]))
+}
+
+func (h *testUUIDHasher) Equal(a, b testUUID) bool {
+	return a == b
+}
+
+// testParityKey is a key type whose text only records whether it is even or
+// odd so that distinct keys marshal to the same text.
+type testParityKey int
+
+func (k testParityKey) MarshalText() ([]byte, error) {
+	if k%2 == 0 {
+		return []byte("even"), nil
+	}
+	return []byte("odd"), nil
+}
+
+func (k *testParityKey) UnmarshalText(text []byte) error {
+	*k = testParityKey(len(text) % 2)
+	return nil
+}
+
+func TestMap_UnmarshalJSON(t *testing.T) {
+	t.Run("StringKeys", func(t *testing.T) {
+		m := NewMap[string, int](nil)
+		for i := 0; i < 1000; i++ {
+			m = m.Set(fmt.Sprint(i), i)
+		}
+
+		buf, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := NewMap[string, int](nil)
+		if err := json.Unmarshal(buf, other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != m.Len() {
+			t.Fatalf("Len()=%d, expected %d", other.Len(), m.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			if v, ok := other.Get(fmt.Sprint(i)); !ok || v != i {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("IntKeys", func(t *testing.T) {
+		m := NewMap[int, string](nil).Set(1, "foo").Set(2, "bar")
+		buf, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other Map[int, string]
+		if err := json.Unmarshal(buf, &other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", other.Len())
+		} else if v, ok := other.Get(2); !ok || v != "bar" {
+			t.Fatalf("Get(2)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		m := NewMap[string, *Map[string, int]](nil)
+		m = m.Set("a", NewMap[string, int](nil).Set("x", 1).Set("y", 2))
+		m = m.Set("b", NewMap[string, int](nil))
+
+		buf, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := NewMap[string, *Map[string, int]](nil)
+		if err := json.Unmarshal(buf, other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", other.Len())
+		}
+		if a, ok := other.Get("a"); !ok || a.Len() != 2 {
+			t.Fatalf("unexpected nested map: %v", ok)
+		} else if v, ok := a.Get("y"); !ok || v != 2 {
+			t.Fatalf("Get(y)=<%v,%v>", v, ok)
+		}
+		if b, ok := other.Get("b"); !ok || b.Len() != 0 {
+			t.Fatalf("unexpected nested empty map: %v", ok)
+		}
+	})
+
+	t.Run("Hasher", func(t *testing.T) {
+		h := &mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		m := NewMap[int, int](h)
+		if err := json.Unmarshal([]byte(`[[1,2],[3,4]]`), m); err != nil {
+			t.Fatal(err)
+		} else if m.hasher != h {
+			t.Fatalf("expected existing hasher to be retained")
+		} else if v, ok := m.Get(3); !ok || v != 4 {
+			t.Fatalf("Get(3)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		if err := json.Unmarshal([]byte(`{"foo":1}`), m); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestSortedMap_Gob(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		m := NewSortedMap[int, string](nil)
+		for i := 999; i >= 0; i-- {
+			m = m.Set(i, fmt.Sprint(i))
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		other := NewSortedMap[int, string](nil)
+		if err := gob.NewDecoder(&buf).Decode(other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != m.Len() {
+			t.Fatalf("Len()=%d, expected %d", other.Len(), m.Len())
+		}
+
+		itr := other.Iterator()
+		for i := 0; i < 1000; i++ {
+			if k, v, ok := itr.Next(); !ok || k != i || v != fmt.Sprint(i) {
+				t.Fatalf("Next()=<%v,%v,%v>, expected key %d", k, v, ok, i)
+			}
+		}
+		if !itr.Done() {
+			t.Fatal("expected iterator done")
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		payload := sortedMapGob[int, string]{Keys: []int{1, 2, 3}, Values: []string{"one"}}
+		if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+			t.Fatal(err)
+		}
+
+		m := NewSortedMap[int, string](nil).Set(10, "ten")
+		if err := m.GobDecode(buf.Bytes()); err == nil || err.Error() != `immutable.SortedMap.GobDecode: 3 keys but 1 values` {
+			t.Fatalf("unexpected error: %v", err)
+		} else if v, ok := m.Get(10); m.Len() != 1 || !ok || v != "ten" {
+			t.Fatal("expected map to be unchanged after a failed decode")
+		}
+	})
+
+	t.Run("Comparer", func(t *testing.T) {
+		reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+		m := NewSortedMap[int, int](reverse).Set(1, 10).Set(3, 30).Set(2, 20)
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		other := NewSortedMap[int, int](reverse)
+		if err := gob.NewDecoder(&buf).Decode(other); err != nil {
+			t.Fatal(err)
+		} else if other.comparer != reverse {
+			t.Fatal("expected existing comparer to be retained")
+		}
+
+		itr := other.Iterator()
+		for _, exp := range []int{3, 2, 1} {
+			if k, v, ok := itr.Next(); !ok || k != exp || v != exp*10 {
+				t.Fatalf("Next()=<%v,%v,%v>, expected key %d", k, v, ok, exp)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(NewSortedMap[string, int](nil)); err != nil {
+			t.Fatal(err)
+		}
+		other := NewSortedMap[string, int](nil).Set("foo", 1)
+		if err := gob.NewDecoder(&buf).Decode(other); err != nil {
+			t.Fatal(err)
+		} else if other.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", other.Len())
+		}
+	})
+}
@@ -39,14 +39,24 @@
 // then simply pass a nil into the constructor. Otherwise you will need to
 // implement a custom Hasher or Comparer type. Please see the provided
 // implementations for reference.
+//
+// # Concurrency
+//
+// List, Map, SortedMap, Set, and SortedSet values may be read and used to
+// derive new collections from any number of goroutines at the same time.
+// Builders and iterators are not safe for concurrent use; each goroutine
+// should use its own.
 package immutable
 
 import (
 	"fmt"
+	"math"
 	"math/bits"
 	"reflect"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/exp/constraints"
 )
@@ -72,6 +82,20 @@ func NewList[T any](values ...T) *List[T] {
 	return l
 }
 
+// NewListRepeat returns a new list containing n copies of value.
+//
+// This function will panic if n is negative.
+func NewListRepeat[T any](value T, n int) *List[T] {
+	if n < 0 {
+		panic(fmt.Sprintf("immutable.NewListRepeat: negative count %d", n))
+	}
+	b := NewListBuilder[T]()
+	for i := 0; i < n; i++ {
+		b.Append(value)
+	}
+	return b.List()
+}
+
 // clone returns a copy of the list.
 func (l *List[T]) clone() *List[T] {
 	other := *l
@@ -83,6 +107,41 @@ func (l *List[T]) Len() int {
 	return l.size
 }
 
+// Len64 returns the number of elements in the list as an int64.
+//
+// The list stores its size as an int so it is limited to math.MaxInt
+// elements, which is math.MaxInt32 on 32-bit platforms. Len64 and GetAt allow
+// callers that track positions as int64 to use them without conversions that
+// could silently truncate.
+func (l *List[T]) Len64() int64 {
+	return int64(l.size)
+}
+
+// GetAt returns the value at the given int64 index. Unlike converting the
+// index to an int, an index which is not representable as an int can never
+// wrap around to a valid position. This method will panic if index is below
+// zero or is greater than or equal to the list size.
+func (l *List[T]) GetAt(index int64) T {
+	if index < 0 || index >= int64(l.size) {
+		panic(fmt.Sprintf("immutable.List.GetAt: index %d out of bounds", index))
+	}
+	return l.Get(int(index))
+}
+
+// Clone returns the list. Lists are immutable so no copy is required and the
+// result can be shared freely between goroutines.
+func (l *List[T]) Clone() *List[T] {
+	return l
+}
+
+// Clear returns an empty list. Returns the original list if it is already empty.
+func (l *List[T]) Clear() *List[T] {
+	if l.Len() == 0 {
+		return l
+	}
+	return NewList[T]()
+}
+
 // cap returns the total number of possible elements for the current depth.
 func (l *List[T]) cap() int {
 	return 1 << (l.root.depth() * listNodeBits)
@@ -97,6 +156,31 @@ func (l *List[T]) Get(index int) T {
 	return l.root.get(l.origin + index)
 }
 
+// Head returns the first element of the list. Returns false if the list is empty.
+func (l *List[T]) Head() (value T, ok bool) {
+	if l.size == 0 {
+		return value, false
+	}
+	return l.Get(0), true
+}
+
+// Tail returns a list of every element except the first. The result shares
+// structure with l. Returns the original list if it is empty.
+func (l *List[T]) Tail() *List[T] {
+	if l.size == 0 {
+		return l
+	}
+	return l.Slice(1, l.size)
+}
+
+// Last returns the last element of the list. Returns false if the list is empty.
+func (l *List[T]) Last() (value T, ok bool) {
+	if l.size == 0 {
+		return value, false
+	}
+	return l.Get(l.size - 1), true
+}
+
 // Set returns a new list with value set at index. Similar to slices, this
 // method will panic if index is below zero or if the index is greater than
 // or equal to the list size.
@@ -104,6 +188,70 @@ func (l *List[T]) Set(index int, value T) *List[T] {
 	return l.set(index, value, false)
 }
 
+// TrySet returns a new list with value set at index. Unlike Set, it does not
+// panic if index is out of bounds and instead returns the original list and
+// false.
+func (l *List[T]) TrySet(index int, value T) (*List[T], bool) {
+	if index < 0 || index >= l.size {
+		return l, false
+	}
+	return l.set(index, value, false), true
+}
+
+// Fill returns a new list with every element between start index and stop
+// index set to value. Returns the original list if the range is empty.
+//
+// Similar to Slice, this method will panic if start or stop are below zero or
+// greater than the list size, or if start is greater than stop.
+func (l *List[T]) Fill(start, stop int, value T) *List[T] {
+	if start < 0 || start > l.size {
+		panic(fmt.Sprintf("immutable.List.Fill: start index %d out of bounds", start))
+	} else if stop < 0 || stop > l.size {
+		panic(fmt.Sprintf("immutable.List.Fill: stop index %d out of bounds", stop))
+	} else if start > stop {
+		panic(fmt.Sprintf("immutable.List.Fill: invalid range: [%d:%d]", start, stop))
+	} else if start == stop {
+		return l
+	}
+
+	// Setting each index copies a path through the tree so rebuild the list
+	// instead when most of it is being overwritten.
+	if stop-start < l.size/2 {
+		other := l
+		for i := start; i < stop; i++ {
+			other = other.set(i, value, false)
+		}
+		return other
+	}
+
+	b := NewListBuilder[T]()
+	itr := l.Iterator()
+	for !itr.Done() {
+		i, v := itr.Next()
+		if i >= start && i < stop {
+			v = value
+		}
+		b.Append(v)
+	}
+	return b.List()
+}
+
+// Swap returns a new list with the elements at i and j exchanged. Returns the
+// original list if i and j are equal.
+//
+// This method will panic if either index is out of bounds.
+func (l *List[T]) Swap(i, j int) *List[T] {
+	if i < 0 || i >= l.size {
+		panic(fmt.Sprintf("immutable.List.Swap: index %d out of bounds", i))
+	} else if j < 0 || j >= l.size {
+		panic(fmt.Sprintf("immutable.List.Swap: index %d out of bounds", j))
+	} else if i == j {
+		return l
+	}
+	vi, vj := l.Get(i), l.Get(j)
+	return l.set(i, vj, false).set(j, vi, false)
+}
+
 func (l *List[T]) set(index int, value T, mutable bool) *List[T] {
 	if index < 0 || index >= l.size {
 		panic(fmt.Sprintf("immutable.List.Set: index %d out of bounds", index))
@@ -116,10 +264,20 @@ func (l *List[T]) set(index int, value T, mutable bool) *List[T] {
 	return other
 }
 
-// Append returns a new list with value added to the end of the list.
+// Append returns a new list with value(s) added to the end of the list.
+// Returns the original list if no values are given.
+//
+// Appending several values in one call is more efficient than calling Append
+// for each value since only a single new list is created.
 func (l *List[T]) Append(values ...T) *List[T] {
-	other := l.clone()
-	for _, value := range values {
+	if len(values) == 0 {
+		return l
+	}
+
+	// The first append copies the path to the last element so the remaining
+	// appends only touch nodes owned by the new list and can update in-place.
+	other := l.append(values[0], false)
+	for _, value := range values[1:] {
 		other.append(value, true)
 	}
 	return other
@@ -145,9 +303,18 @@ func (l *List[T]) append(value T, mutable bool) *List[T] {
 }
 
 // Prepend returns a new list with value(s) added to the beginning of the list.
+// Returns the original list if no values are given.
+//
+// Existing elements are not moved so prepending has the same cost as appending.
 func (l *List[T]) Prepend(values ...T) *List[T] {
-	other := l.clone()
-	for i := len(values) - 1; i >= 0; i-- {
+	if len(values) == 0 {
+		return l
+	}
+
+	// The first prepend copies the path to the first element so the remaining
+	// prepends only touch nodes owned by the new list and can update in-place.
+	other := l.prepend(values[len(values)-1], false)
+	for i := len(values) - 2; i >= 0; i-- {
 		other.prepend(values[i], true)
 	}
 	return other
@@ -174,6 +341,58 @@ func (l *List[T]) prepend(value T, mutable bool) *List[T] {
 	return other
 }
 
+// Insert returns a new list with value inserted before index. Elements at and
+// after index are shifted right. If index is equal to the list size then value
+// is appended. This method will panic if index is below zero or greater than
+// the list size.
+//
+// The list does not support inserting into the middle directly so the shorter
+// side of the list is copied, which is O(n) in the worst case.
+func (l *List[T]) Insert(index int, value T) *List[T] {
+	if index < 0 || index > l.size {
+		panic(fmt.Sprintf("immutable.List.Insert: index %d out of bounds", index))
+	}
+
+	if index < l.size/2 {
+		values := append(l.elems(0, index), value)
+		return l.Slice(index, l.size).Prepend(values...)
+	}
+	values := append([]T{value}, l.elems(index, l.size)...)
+	return l.Slice(0, index).Append(values...)
+}
+
+// Delete returns a new list with the element at index removed. Elements after
+// index are shifted left. This method will panic if index is below zero or if
+// the index is greater than or equal to the list size.
+//
+// As with Insert, the shorter side of the list is copied.
+func (l *List[T]) Delete(index int) *List[T] {
+	if index < 0 || index >= l.size {
+		panic(fmt.Sprintf("immutable.List.Delete: index %d out of bounds", index))
+	}
+
+	if index < l.size/2 {
+		return l.Slice(index+1, l.size).Prepend(l.elems(0, index)...)
+	}
+	return l.Slice(0, index).Append(l.elems(index+1, l.size)...)
+}
+
+// elems returns the elements between start and end index as a slice.
+func (l *List[T]) elems(start, end int) []T {
+	values := make([]T, 0, end-start)
+	if start == end {
+		return values
+	}
+
+	itr := l.Iterator()
+	itr.Seek(start)
+	for i := start; i < end; i++ {
+		_, v := itr.Next()
+		values = append(values, v)
+	}
+	return values
+}
+
 // Slice returns a new list of elements between start index and end index.
 // Similar to slices, this method will panic if start or end are below zero or
 // greater than the list size. A panic will also occur if start is greater than
@@ -185,6 +404,21 @@ func (l *List[T]) Slice(start, end int) *List[T] {
 	return l.slice(start, end, false)
 }
 
+// Truncate returns a list of the first length elements of l. Returns the
+// original list if length is greater than or equal to its size.
+//
+// This method will panic if length is negative.
+func (l *List[T]) Truncate(length int) *List[T] {
+	if length < 0 {
+		panic(fmt.Sprintf("immutable.List.Truncate: length %d out of bounds", length))
+	} else if length >= l.size {
+		return l
+	} else if length == 0 {
+		return l.Clear()
+	}
+	return l.Slice(0, length)
+}
+
 func (l *List[T]) slice(start, end int, mutable bool) *List[T] {
 	// Panics similar to Go slices.
 	if start < 0 || start > l.size {
@@ -209,25 +443,31 @@ func (l *List[T]) slice(start, end int, mutable bool) *List[T] {
 	// Update origin/size.
 	other.origin = l.origin + start
 	other.size = end - start
+	other.compact(mutable)
+	return other
+}
 
+// compact contracts the tree while the list's elements are in a single child
+// of the root and removes any nodes outside of the elements. Only the list
+// itself is updated in-place so it must not be shared. Nodes are only updated
+// in-place if mutable is true.
+func (l *List[T]) compact(mutable bool) {
 	// Contract tree while the start & end are in the same child node.
-	for other.root.depth() > 1 {
-		i := (other.origin >> (other.root.depth() * listNodeBits)) & listNodeMask
-		j := ((other.origin + other.size - 1) >> (other.root.depth() * listNodeBits)) & listNodeMask
+	for l.root.depth() > 1 {
+		i := (l.origin >> (l.root.depth() * listNodeBits)) & listNodeMask
+		j := ((l.origin + l.size - 1) >> (l.root.depth() * listNodeBits)) & listNodeMask
 		if i != j {
 			break // branch contains at least two nodes, exit
 		}
 
 		// Replace the current root with the single child & update origin offset.
-		other.origin -= i << (other.root.depth() * listNodeBits)
-		other.root = other.root.(*listBranchNode[T]).children[i]
+		l.origin -= i << (l.root.depth() * listNodeBits)
+		l.root = l.root.(*listBranchNode[T]).children[i]
 	}
 
 	// Ensure all references are removed before start & after end.
-	other.root = other.root.deleteBefore(other.origin, mutable)
-	other.root = other.root.deleteAfter(other.origin+other.size-1, mutable)
-
-	return other
+	l.root = l.root.deleteBefore(l.origin, mutable)
+	l.root = l.root.deleteAfter(l.origin+l.size-1, mutable)
 }
 
 // Iterator returns a new iterator for this list positioned at the first index.
@@ -237,9 +477,262 @@ func (l *List[T]) Iterator() *ListIterator[T] {
 	return itr
 }
 
+// Concat returns a new list with the elements of other added after the
+// elements of l. If either list is empty then the other list is returned.
+//
+// The larger of the two lists is reused and the elements of the smaller list
+// are appended or prepended to it.
+func (l *List[T]) Concat(other *List[T]) *List[T] {
+	if other == nil || other.Len() == 0 {
+		return l
+	} else if l.Len() == 0 {
+		return other
+	}
+
+	// The first element is added with a path copy so the builder only updates
+	// nodes owned by the new list.
+	var b *ListBuilder[T]
+	if l.Len() >= other.Len() {
+		itr := other.Iterator()
+		_, v := itr.Next()
+		b = &ListBuilder[T]{list: l.append(v, false)}
+		for !itr.Done() {
+			_, v := itr.Next()
+			b.Append(v)
+		}
+	} else {
+		itr := l.Iterator()
+		itr.Last()
+		_, v := itr.Prev()
+		b = &ListBuilder[T]{list: other.prepend(v, false)}
+		for !itr.Done() {
+			_, v := itr.Prev()
+			b.Prepend(v)
+		}
+	}
+	return b.List()
+}
+
+// Reverse returns a new list with the elements of l in reverse order. Lists
+// with fewer than two elements are returned unchanged.
+func (l *List[T]) Reverse() *List[T] {
+	if l.Len() < 2 {
+		return l
+	}
+
+	b := NewListBuilder[T]()
+	itr := l.Iterator()
+	itr.Last()
+	for !itr.Done() {
+		_, v := itr.Prev()
+		b.Append(v)
+	}
+	return b.List()
+}
+
+// IndexOf returns the index of the first element for which eq(element, value)
+// returns true, or -1 if no element matches.
+func (l *List[T]) IndexOf(value T, eq func(a, b T) bool) int {
+	itr := l.Iterator()
+	for !itr.Done() {
+		i, v := itr.Next()
+		if eq(v, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find returns the first element for which pred returns true along with its
+// index. Returns the zero value, -1, and false if no element matches.
+func (l *List[T]) Find(pred func(value T) bool) (value T, index int, ok bool) {
+	itr := l.Iterator()
+	for !itr.Done() {
+		i, v := itr.Next()
+		if pred(v) {
+			return v, i, true
+		}
+	}
+	return value, -1, false
+}
+
+// Contains returns true if eq(element, value) returns true for any element.
+func (l *List[T]) Contains(value T, eq func(a, b T) bool) bool {
+	return l.IndexOf(value, eq) != -1
+}
+
+// Filter returns a list containing the elements for which pred returns true,
+// in their original order. If every element is retained then the original
+// list is returned.
+func (l *List[T]) Filter(pred func(value T) bool) *List[T] {
+	b := NewListBuilder[T]()
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		if pred(v) {
+			b.Append(v)
+		}
+	}
+	if b.Len() == l.Len() {
+		return l
+	}
+	return b.List()
+}
+
+// ForEach calls f for each element of the list in index order. Iteration
+// stops early if f returns false.
+func (l *List[T]) ForEach(f func(index int, value T) bool) {
+	itr := l.Iterator()
+	for !itr.Done() {
+		if !f(itr.Next()) {
+			return
+		}
+	}
+}
+
+// Equal returns true if l and other have the same length and eq returns true
+// for each pair of elements at the same index.
+func (l *List[T]) Equal(other *List[T], eq func(a, b T) bool) bool {
+	if l == other {
+		return true
+	} else if l.Len() != other.Len() {
+		return false
+	}
+
+	itr, otherItr := l.Iterator(), other.Iterator()
+	for !itr.Done() {
+		_, a := itr.Next()
+		_, b := otherItr.Next()
+		if !eq(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListEqual returns true if a and b have the same length and equal elements at
+// each index, using ==.
+func ListEqual[T comparable](a, b *List[T]) bool {
+	return a.Equal(b, func(x, y T) bool { return x == y })
+}
+
+// ListChunk returns a list of consecutive sub-lists of l, each containing size
+// elements except the last, which may be smaller. Returns an empty list if l
+// is empty.
+//
+// This function will panic if size is not positive.
+func ListChunk[T any](l *List[T], size int) *List[*List[T]] {
+	if size <= 0 {
+		panic(fmt.Sprintf("immutable.ListChunk: invalid chunk size %d", size))
+	}
+
+	chunks := NewListBuilder[*List[T]]()
+	var chunk *ListBuilder[T]
+	itr := l.Iterator()
+	for !itr.Done() {
+		if chunk == nil {
+			chunk = NewListBuilder[T]()
+		}
+		_, v := itr.Next()
+		if chunk.Append(v); chunk.Len() == size {
+			chunks.Append(chunk.List())
+			chunk = nil
+		}
+	}
+	if chunk != nil {
+		chunks.Append(chunk.List())
+	}
+	return chunks.List()
+}
+
+// ListSort returns a new list containing the elements of l sorted by less. The
+// elements are copied into a scratch slice, sorted with sort.Slice, and then
+// rebuilt into a list. The sort is not stable; use ListSortStable to keep
+// equal elements in their original order. l is not modified.
+func ListSort[T any](l *List[T], less func(a, b T) bool) *List[T] {
+	return listSort(l, less, sort.Slice)
+}
+
+// ListSortStable is like ListSort but uses sort.SliceStable so that equal
+// elements keep their original order.
+func ListSortStable[T any](l *List[T], less func(a, b T) bool) *List[T] {
+	return listSort(l, less, sort.SliceStable)
+}
+
+func listSort[T any](l *List[T], less func(a, b T) bool, sortFn func(x any, less func(i, j int) bool)) *List[T] {
+	values := l.elems(0, l.Len())
+	sortFn(values, func(i, j int) bool { return less(values[i], values[j]) })
+
+	b := NewListBuilder[T]()
+	for _, v := range values {
+		b.Append(v)
+	}
+	return b.List()
+}
+
+// ListToMap returns a map from keyFn of each element of l to the element,
+// using hasher to hash keys. If several elements have the same key then the
+// last one in the list wins. If hasher is nil then a default hasher is used,
+// as with NewMap.
+func ListToMap[T any, K comparable](l *List[T], hasher Hasher[K], keyFn func(value T) K) *Map[K, T] {
+	b := NewMapBuilder[K, T](hasher)
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		b.Set(keyFn(v), v)
+	}
+	return b.Map()
+}
+
+// ListGroupBy returns a map from keyFn of each element of l to a list of the
+// elements with that key, in the same order as l. See ListToMap for hasher.
+func ListGroupBy[T any, K comparable](l *List[T], hasher Hasher[K], keyFn func(value T) K) *Map[K, *List[T]] {
+	b := NewMapBuilder[K, *List[T]](hasher)
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		k := keyFn(v)
+		group, ok := b.Get(k)
+		if !ok {
+			group = NewList[T]()
+		}
+		b.Set(k, group.Append(v))
+	}
+	return b.Map()
+}
+
+// ListMap returns a new list containing the result of f for each element of
+// src, in the same order. A nil src returns an empty list.
+func ListMap[T, U any](src *List[T], f func(value T) U) *List[U] {
+	b := NewListBuilder[U]()
+	if src != nil {
+		itr := src.Iterator()
+		for !itr.Done() {
+			_, v := itr.Next()
+			b.Append(f(v))
+		}
+	}
+	return b.List()
+}
+
+// ListReduce folds the elements of l into a single value. Starting with
+// initial, f is called with the accumulated value and each element in index
+// order, so the fold is left-associative: f(f(f(initial, l[0]), l[1]), l[2]).
+func ListReduce[T, A any](l *List[T], initial A, f func(acc A, value T) A) A {
+	acc := initial
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		acc = f(acc, v)
+	}
+	return acc
+}
+
 // ListBuilder represents an efficient builder for creating new Lists.
+// A ListBuilder is not safe for concurrent use.
 type ListBuilder[T any] struct {
-	list *List[T] // current state
+	list     *List[T] // current state
+	reserved bool     // true if nodes were preallocated by NewListBuilderSized
 }
 
 // NewListBuilder returns a new instance of ListBuilder.
@@ -247,12 +740,71 @@ func NewListBuilder[T any]() *ListBuilder[T] {
 	return &ListBuilder[T]{list: NewList[T]()}
 }
 
+// NewListBuilderSized returns a new instance of ListBuilder for a list that is
+// expected to hold about sizeHint elements.
+//
+// The trie is created at the depth needed for sizeHint elements and the nodes
+// that will hold them are allocated together, one allocation per level, so
+// appending up to sizeHint elements does not allocate. Reserved nodes that are
+// not used are released when the list is fetched.
+func NewListBuilderSized[T any](sizeHint int) *ListBuilder[T] {
+	b := NewListBuilder[T]()
+	if sizeHint > 1 {
+		b.list.root, b.reserved = newListNodes[T](sizeHint), true
+	}
+	return b
+}
+
+// newListNodes returns a root node with every node needed to append n
+// elements to an empty list. The root is at the depth that appending would
+// grow it to and the nodes at each level are allocated in a single slice.
+func newListNodes[T any](n int) listNode[T] {
+	// Find the depth at which appending n elements does not expand the root.
+	var depth uint
+	for 1<<(depth*listNodeBits) < n {
+		depth++
+	}
+
+	// Allocate enough leaves for n elements.
+	leaves := make([]listLeafNode[T], (n+listNodeSize-1)/listNodeSize)
+	nodes := make([]listNode[T], len(leaves))
+	for i := range leaves {
+		nodes[i] = &leaves[i]
+	}
+
+	// Group the nodes of each level under branches until one root remains.
+	for d := uint(1); d <= depth; d++ {
+		branches := make([]listBranchNode[T], (len(nodes)+listNodeSize-1)/listNodeSize)
+		for i := range branches {
+			branches[i].d = d
+		}
+		for i, node := range nodes {
+			branches[i/listNodeSize].children[i%listNodeSize] = node
+		}
+
+		nodes = nodes[:len(branches)]
+		for i := range branches {
+			nodes[i] = &branches[i]
+		}
+	}
+	return nodes[0]
+}
+
 // List returns the current copy of the list.
 // The builder should not be used again after the list after this call.
 func (b *ListBuilder[T]) List() *List[T] {
 	assert(b.list != nil, "immutable.ListBuilder.List(): duplicate call to fetch list")
 	list := b.list
 	b.list = nil
+
+	// Release any reserved nodes past the end of the list.
+	if b.reserved {
+		if list.size == 0 {
+			list.root, list.origin = &listLeafNode[T]{}, 0
+		} else {
+			list.compact(true)
+		}
+	}
 	return list
 }
 
@@ -576,13 +1128,16 @@ func (itr *ListIterator[T]) Last() {
 	}
 }
 
-// Seek moves the iterator position to the given index in the list.
-// Similar to Go slices, this method will panic if index is below zero or if
-// the index is greater than or equal to the list size.
+// Seek moves the iterator position to the given index in the list. An index
+// below zero is clamped to the first element and an index greater than or
+// equal to the list size moves the iterator to the end so it is done.
 func (itr *ListIterator[T]) Seek(index int) {
-	// Panic similar to Go slices.
-	if index < 0 || index >= itr.list.Len() {
-		panic(fmt.Sprintf("immutable.ListIterator.Seek: index %d out of bounds", index))
+	if index < 0 {
+		index = 0
+	}
+	if n := itr.list.Len(); index >= n {
+		itr.index = n
+		return
 	}
 	itr.index = index
 
@@ -691,45 +1246,163 @@ const (
 // Map represents an immutable hash map implementation. The map uses a Hasher
 // to generate hashes and check for equality of key values.
 //
+// A nil *Map is treated as an empty map by read methods such as Len, Get, and
+// Iterator, so an uninitialized map can be passed around safely for reads.
+//
 // It is implemented as an Hash Array Mapped Trie.
 type Map[K comparable, V any] struct {
 	size   int           // total number of key/value pairs
 	root   mapNode[K, V] // root node of trie
 	hasher Hasher[K]     // hasher implementation
+	edit   *mapEdit      // owner of nodes that builders may update in-place
 }
 
 // NewMap returns a new instance of Map. If hasher is nil, a default hasher
 // implementation will automatically be chosen based on the first key added.
-// Default hasher implementations only exist for int, string, and byte slice types.
+// Default hasher implementations only exist for string, integer, floating-point,
+// and bool types. See NewHasher for details.
 func NewMap[K comparable, V any](hasher Hasher[K]) *Map[K, V] {
 	return &Map[K, V]{
 		hasher: hasher,
 	}
 }
 
+// NewMapWithSeed returns a new instance of Map which mixes seed into the hash
+// of every key. Maps derived from the returned map use the same seed. This
+// makes it difficult for untrusted keys to be chosen so that they collide.
+// See NewSeededHasher for details.
+func NewMapWithSeed[K comparable, V any](hasher Hasher[K], seed uint64) *Map[K, V] {
+	return NewMap[K, V](NewSeededHasher(hasher, seed))
+}
+
 // Len returns the number of elements in the map.
 func (m *Map[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
 	return m.size
 }
 
-// clone returns a shallow copy of m.
+// Clone returns the map. Maps are immutable so no copy is required and the
+// result can be shared freely between goroutines.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	return m
+}
+
+// Clear returns an empty map that uses the same hasher as m. Returns the
+// original map if it is already empty.
+func (m *Map[K, V]) Clear() *Map[K, V] {
+	if m.Len() == 0 {
+		return m
+	}
+	return NewMap[K, V](m.hasher)
+}
+
+// Hasher returns the hasher used by the map. If the map was created without a
+// hasher then this returns nil until a default hasher is chosen by the first Set.
+func (m *Map[K, V]) Hasher() Hasher[K] {
+	if m == nil {
+		return nil
+	}
+	return m.hasher
+}
+
+// clone returns a shallow copy of m. The copy does not own any nodes so its
+// nodes are copied before they are updated by a builder.
 func (m *Map[K, V]) clone() *Map[K, V] {
 	other := *m
+	other.edit = nil
 	return &other
 }
 
+// editor returns the owner used for in-place updates if mutable is true.
+// Otherwise returns nil so every updated node is copied.
+func (m *Map[K, V]) editor(mutable bool) *mapEdit {
+	if !mutable {
+		return nil
+	} else if m.edit == nil {
+		m.edit = &mapEdit{}
+	}
+	return m.edit
+}
+
 // Get returns the value for a given key and a flag indicating whether the
 // key exists. This flag distinguishes a nil value set on a key versus a
 // non-existent key in the map.
 func (m *Map[K, V]) Get(key K) (value V, ok bool) {
-	var empty V
-	if m.root == nil {
-		return empty, false
+	_, value, ok = m.GetEntry(key)
+	return value, ok
+}
+
+// GetEntry returns the key stored in the map along with its value. The stored
+// key may be a different instance than key if the hasher considers them equal.
+// This is useful for interning keys.
+func (m *Map[K, V]) GetEntry(key K) (storedKey K, value V, ok bool) {
+	if m == nil || m.root == nil {
+		return storedKey, value, false
 	}
 	keyHash := m.hasher.Hash(key)
 	return m.root.get(key, 0, keyHash, m.hasher)
 }
 
+// GetBatch looks up each of keys and returns the pairs that exist in found and
+// the keys that do not in missing. Duplicate keys are looked up once, and
+// missing lists each absent key once in the order it first appears. The
+// result is keyed by the requested keys using Go equality rather than the
+// map's hasher.
+func (m *Map[K, V]) GetBatch(keys []K) (found map[K]V, missing []K) {
+	found = make(map[K]V, len(keys))
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if value, ok := m.Get(key); ok {
+			found[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// GetDefault returns the value for a given key or fallback if the key does not
+// exist. A zero value stored for the key is returned rather than fallback.
+func (m *Map[K, V]) GetDefault(key K, fallback V) V {
+	if value, ok := m.Get(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// GetOrElse returns the value for a given key or the result of f if the key
+// does not exist. f is only called when the key is missing.
+func (m *Map[K, V]) GetOrElse(key K, f func() V) V {
+	if value, ok := m.Get(key); ok {
+		return value
+	}
+	return f()
+}
+
+// ComputeIfAbsent returns the value for key and the original map if the key
+// exists. Otherwise it returns the result of f and a new map with key set to
+// that value. f is only called when the key is missing, including when a zero
+// value is stored for the key.
+func (m *Map[K, V]) ComputeIfAbsent(key K, f func(key K) V) (V, *Map[K, V]) {
+	var value V
+	other := m.update(key, func(old V, existed bool) (V, mapUpdateOp) {
+		if existed {
+			value = old
+			return old, mapUpdateNone
+		}
+		value = f(key)
+		return value, mapUpdateSet
+	})
+	return value, other
+}
+
 // Set returns a map with the key set to the new value. A nil value is allowed.
 //
 // This function will return a new map even if the updated value is the same as
@@ -751,39 +1424,105 @@ func (m *Map[K, V]) set(key K, value V, mutable bool) *Map[K, V] {
 		other = m.clone()
 	}
 	other.hasher = hasher
+	edit := other.editor(mutable)
 
 	// If the map is empty, initialize with a simple array node.
 	if m.root == nil {
 		other.size = 1
-		other.root = &mapArrayNode[K, V]{entries: []mapEntry[K, V]{{key: key, value: value}}}
+		other.root = &mapArrayNode[K, V]{edit: edit, entries: []mapEntry[K, V]{{key: key, value: value}}}
 		return other
 	}
 
 	// Otherwise copy the map and delegate insertion to the root.
 	// Resized will return true if the key does not currently exist.
 	var resized bool
-	other.root = m.root.set(key, value, 0, hasher.Hash(key), hasher, mutable, &resized)
+	other.root = m.root.set(key, value, 0, hasher.Hash(key), hasher, edit, &resized)
 	if resized {
 		other.size++
 	}
 	return other
 }
 
+// initHashArrayRoot sets the root of an empty map to a hash-array node holding
+// a single key/value pair. Used by builders which expect many keys. The map is
+// updated in-place so it must not be shared.
+func (m *Map[K, V]) initHashArrayRoot(key K, value V) {
+	if m.hasher == nil {
+		m.hasher = NewHasher(key)
+	}
+	keyHash := m.hasher.Hash(key)
+	edit := m.editor(true)
+
+	root := &mapHashArrayNode[K, V]{edit: edit, count: 1}
+	root.nodes[keyHash&mapNodeMask] = newMapValueNode(edit, keyHash, key, value)
+	m.root, m.size = root, 1
+}
+
 // Delete returns a map with the given key removed.
 // Removing a non-existent key will cause this method to return the same map.
 func (m *Map[K, V]) Delete(key K) *Map[K, V] {
 	return m.delete(key, false)
 }
 
+// Update returns a map with the value for key replaced by the result of f.
+// f receives the current value and whether the key exists, and returns the
+// new value and whether to keep it. If keep is false then the key is removed.
+// If keep is false and the key does not exist then the original map is returned.
+func (m *Map[K, V]) Update(key K, f func(old V, existed bool) (value V, keep bool)) *Map[K, V] {
+	return m.update(key, func(old V, existed bool) (V, mapUpdateOp) {
+		value, keep := f(old, existed)
+		if !keep {
+			return value, mapUpdateDelete
+		}
+		return value, mapUpdateSet
+	})
+}
+
+// update calls f with the current value of key and applies the change it
+// returns, finding and copying the path to the key in a single traversal.
+// Returns the original map if nothing changes.
+func (m *Map[K, V]) update(key K, f mapUpdateFunc[V]) *Map[K, V] {
+	// Delegate to set on an empty map since there is no path to copy.
+	if m.root == nil {
+		var zero V
+		if value, op := f(zero, false); op == mapUpdateSet {
+			return m.set(key, value, false)
+		}
+		return m
+	}
+
+	// Track the change made so the size can be updated.
+	var existed bool
+	var op mapUpdateOp
+	newRoot := m.root.update(key, 0, m.hasher.Hash(key), m.hasher, func(old V, ok bool) (V, mapUpdateOp) {
+		var value V
+		existed = ok
+		value, op = f(old, ok)
+		return value, op
+	})
+	if newRoot == m.root {
+		return m
+	}
+
+	other := m.clone()
+	other.root = newRoot
+	if !existed && op == mapUpdateSet {
+		other.size++
+	} else if existed && op == mapUpdateDelete {
+		other.size--
+	}
+	return other
+}
+
 func (m *Map[K, V]) delete(key K, mutable bool) *Map[K, V] {
 	// Return original map if no keys exist.
-	if m.root == nil {
+	if m == nil || m.root == nil {
 		return m
 	}
 
 	// If the delete did not change the node then return the original map.
 	var resized bool
-	newRoot := m.root.delete(key, 0, m.hasher.Hash(key), m.hasher, mutable, &resized)
+	newRoot := m.root.delete(key, 0, m.hasher.Hash(key), m.hasher, m.editor(mutable), &resized)
 	if !resized {
 		return m
 	}
@@ -807,20 +1546,422 @@ func (m *Map[K, V]) Iterator() *MapIterator[K, V] {
 	return itr
 }
 
-// MapBuilder represents an efficient builder for creating Maps.
-type MapBuilder[K comparable, V any] struct {
-	m *Map[K, V] // current state
+// IteratorSorted returns a new iterator over the key/value pairs of the map
+// ordered by less. This provides a reproducible order without using a
+// SortedMap, at the cost of copying and sorting every pair when called.
+func (m *Map[K, V]) IteratorSorted(less func(a, b K) bool) *MapSortedIterator[K, V] {
+	entries := make([]mapEntry[K, V], 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		entries = append(entries, mapEntry[K, V]{key: k, value: v})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i].key, entries[j].key)
+	})
+	return &MapSortedIterator[K, V]{entries: entries}
 }
 
-// NewMapBuilder returns a new instance of MapBuilder.
+// Filter returns a map containing only the key/value pairs for which pred
+// returns true. If every pair is retained then the original map is returned.
+func (m *Map[K, V]) Filter(pred func(key K, value V) bool) *Map[K, V] {
+	if m == nil {
+		return m
+	}
+
+	b := NewMapBuilder[K, V](m.hasher)
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if pred(k, v) {
+			b.Set(k, v)
+		}
+	}
+	if b.Len() == m.Len() {
+		return m
+	}
+	return b.Map()
+}
+
+// DeleteIf returns a map with every key/value pair for which pred returns true
+// removed. If no pairs are removed then the original map is returned.
+func (m *Map[K, V]) DeleteIf(pred func(key K, value V) bool) *Map[K, V] {
+	return m.Filter(func(key K, value V) bool {
+		return !pred(key, value)
+	})
+}
+
+// Partition splits m into two maps in a single pass. matched contains the
+// key/value pairs for which pred returns true and rest contains the others.
+// Both maps use the hasher of m. If every pair falls on one side then the
+// original map is returned for that side.
+func (m *Map[K, V]) Partition(pred func(key K, value V) bool) (matched, rest *Map[K, V]) {
+	if m == nil {
+		return m, m
+	}
+
+	mb, rb := NewMapBuilder[K, V](m.hasher), NewMapBuilder[K, V](m.hasher)
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if pred(k, v) {
+			mb.Set(k, v)
+		} else {
+			rb.Set(k, v)
+		}
+	}
+
+	matched, rest = mb.Map(), rb.Map()
+	if matched.Len() == m.Len() {
+		matched = m
+	} else if rest.Len() == m.Len() {
+		rest = m
+	}
+	return matched, rest
+}
+
+// MapValues returns a map with the same keys as m and each value replaced by
+// the result of f. Because keys are unchanged, the new map is built with the
+// same trie structure as m and keys are not rehashed.
+func (m *Map[K, V]) MapValues(f func(key K, value V) V) *Map[K, V] {
+	if m == nil {
+		return m
+	}
+
+	other := m.clone()
+	if m.root != nil {
+		other.root = m.root.mapValues(f)
+	}
+	return other
+}
+
+// Merge returns a map containing the key/value pairs of both m and other.
+// Pairs from other are set on m so the result shares structure with m. When a
+// key exists in both maps, its value is the result of resolve(key, a, b) where
+// a is the value from m and b is the value from other. If resolve is nil then
+// the value from other is used.
+func (m *Map[K, V]) Merge(other *Map[K, V], resolve func(key K, a, b V) V) *Map[K, V] {
+	if other.Len() == 0 {
+		return m
+	} else if m.Len() == 0 {
+		return other
+	}
+
+	result := m
+	itr := other.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if resolve != nil {
+			if prev, ok := m.Get(k); ok {
+				v = resolve(k, prev, v)
+			}
+		}
+		result = result.Set(k, v)
+	}
+	return result
+}
+
+// Count returns the number of key/value pairs for which pred returns true.
+// It can be used to pre-size a slice before a second pass over the map.
+func (m *Map[K, V]) Count(pred func(key K, value V) bool) int {
+	var n int
+	itr := m.Iterator()
+	for !itr.Done() {
+		if k, v, _ := itr.Next(); pred(k, v) {
+			n++
+		}
+	}
+	return n
+}
+
+// CollectKeys returns the keys of the pairs for which pred returns true, in
+// the same order as MapIterator. Unlike Filter, no intermediate map is built.
+func (m *Map[K, V]) CollectKeys(pred func(key K, value V) bool) []K {
+	keys := make([]K, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		if k, v, _ := itr.Next(); pred(k, v) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// CollectValues returns the values of the pairs for which pred returns true,
+// in the same order as MapIterator.
+func (m *Map[K, V]) CollectValues(pred func(key K, value V) bool) []V {
+	values := make([]V, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		if k, v, _ := itr.Next(); pred(k, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ForEach calls f for each key/value pair in the map, in the same order as
+// MapIterator. Iteration stops early if f returns false.
+func (m *Map[K, V]) ForEach(f func(key K, value V) bool) {
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Equal returns true if m and other contain the same keys and valEq returns
+// true for the values of each key. Keys are looked up in other using its hasher.
+func (m *Map[K, V]) Equal(other *Map[K, V], valEq func(a, b V) bool) bool {
+	if m == other {
+		return true
+	} else if m.Len() != other.Len() {
+		return false
+	}
+
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, a, _ := itr.Next()
+		if b, ok := other.Get(k); !ok || !valEq(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// MapEqual returns true if a and b contain the same keys with equal values,
+// using == to compare values.
+func MapEqual[K, V comparable](a, b *Map[K, V]) bool {
+	return a.Equal(b, func(x, y V) bool { return x == y })
+}
+
+// MapDiff compares two versions of a map. added contains the pairs of next
+// whose keys are not in prev, removed contains the pairs of prev whose keys
+// are not in next, and changed contains the pairs of next whose keys are in
+// prev but whose values differ according to valEq. Keys are looked up using
+// the hasher of the map being searched. A nil map is treated as empty.
+func MapDiff[K comparable, V any](prev, next *Map[K, V], valEq func(a, b V) bool) (added, removed, changed *Map[K, V]) {
+	// Results use the hasher of the map they are taken from, falling back to
+	// the other map's hasher if that map is nil or has no hasher yet.
+	var prevHasher, nextHasher Hasher[K]
+	if prev != nil {
+		prevHasher = prev.hasher
+	}
+	if next != nil {
+		nextHasher = next.hasher
+	}
+	if prevHasher == nil {
+		prevHasher = nextHasher
+	} else if nextHasher == nil {
+		nextHasher = prevHasher
+	}
+
+	ab := NewMapBuilder[K, V](nextHasher)
+	cb := NewMapBuilder[K, V](nextHasher)
+	itr := next.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if old, ok := prev.Get(k); !ok {
+			ab.Set(k, v)
+		} else if !valEq(old, v) {
+			cb.Set(k, v)
+		}
+	}
+
+	rb := NewMapBuilder[K, V](prevHasher)
+	itr = prev.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if _, ok := next.Get(k); !ok {
+			rb.Set(k, v)
+		}
+	}
+	return ab.Map(), rb.Map(), cb.Map()
+}
+
+// MapInvert returns a map from each value of m to its key, using hasher to
+// hash the values. If hasher is nil then a default hasher is used, as with
+// NewMap. If several keys map to equal values then the key that comes last in
+// MapIterator order wins.
+func MapInvert[K, V comparable](m *Map[K, V], hasher Hasher[V]) *Map[V, K] {
+	b := NewMapBuilderSized[V, K](hasher, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		b.Set(v, k)
+	}
+	return b.Map()
+}
+
+// Keys returns a set containing the keys of the map. The set uses the same
+// hasher as the map.
+func (m *Map[K, V]) Keys() Set[K] {
+	b := NewSetBuilder[K](m.Hasher())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, _, _ := itr.Next()
+		b.Set(k)
+	}
+	return b.s
+}
+
+// Values returns a list containing the values of the map. Values are in the
+// same order as MapIterator, which is unspecified.
+func (m *Map[K, V]) Values() *List[V] {
+	b := NewListBuilder[V]()
+	itr := m.Iterator()
+	for !itr.Done() {
+		_, v, _ := itr.Next()
+		b.Append(v)
+	}
+	return b.List()
+}
+
+// Entry is a key/value pair.
+type Entry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// Entries returns a list containing the key/value pairs of the map. Entries
+// are in the same order as MapIterator, which is unspecified.
+func (m *Map[K, V]) Entries() *List[Entry[K, V]] {
+	b := NewListBuilder[Entry[K, V]]()
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		b.Append(Entry[K, V]{Key: k, Value: v})
+	}
+	return b.List()
+}
+
+// String returns a string representation of the map in iteration order, such
+// as "Map{k1:v1, k2:v2}". Only the first 100 pairs are included.
+func (m *Map[K, V]) String() string {
+	w := newElemWriter("Map")
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if !w.write("%v:%v", k, v) {
+			break
+		}
+	}
+	return w.String()
+}
+
+// MapStats describes the internal structure of a Map.
+type MapStats struct {
+	Nodes  int // total number of nodes, including leaves
+	Leaves int // number of nodes which hold key/value pairs
+	Depth  int // number of levels from the root to the deepest leaf
+}
+
+// Stats returns statistics about the internal nodes of m. Maps derived from
+// one another share nodes, so the counts of two maps should not be summed to
+// estimate their combined size.
+func (m *Map[K, V]) Stats() MapStats {
+	var stats MapStats
+	m.walk(func(n mapNode[K, V], depth int) {
+		stats.Nodes++
+		switch n.(type) {
+		case *mapArrayNode[K, V], *mapValueNode[K, V], *mapHashCollisionNode[K, V]:
+			stats.Leaves++
+		}
+		if depth > stats.Depth {
+			stats.Depth = depth
+		}
+	})
+	return stats
+}
+
+// walk calls f for every node in the map. The root node has a depth of 1.
+func (m *Map[K, V]) walk(f func(n mapNode[K, V], depth int)) {
+	var walk func(n mapNode[K, V], depth int)
+	walk = func(n mapNode[K, V], depth int) {
+		f(n, depth)
+		switch n := n.(type) {
+		case *mapBitmapIndexedNode[K, V]:
+			for _, child := range n.nodes {
+				walk(child, depth+1)
+			}
+		case *mapHashArrayNode[K, V]:
+			for _, child := range n.nodes {
+				if child != nil {
+					walk(child, depth+1)
+				}
+			}
+		}
+	}
+	if m != nil && m.root != nil {
+		walk(m.root, 1)
+	}
+}
+
+// Mutable returns a builder seeded with the contents of m so that many edits
+// can be applied before freezing the result with MapBuilder.Map. Edits made
+// through the builder do not affect m.
+//
+// The builder shares the nodes of m and copies each node the first time an
+// edit reaches it. Later edits update the copied nodes in-place, so the cost
+// is proportional to the number of nodes edited rather than the size of m.
+func (m *Map[K, V]) Mutable() *MapBuilder[K, V] {
+	return &MapBuilder[K, V]{m: m.clone()}
+}
+
+// WithMutations applies the edits made by f to a builder seeded from m and
+// returns the resulting map. This avoids creating a snapshot for each edit
+// without managing the builder's lifecycle by hand. m is not affected.
+//
+// The builder must not be retained or frozen by f.
+func (m *Map[K, V]) WithMutations(f func(b *MapBuilder[K, V])) *Map[K, V] {
+	b := m.Mutable()
+	f(b)
+	return b.Map()
+}
+
+// Rehash returns a copy of m rebuilt with newHasher, for example to replace
+// a hasher that produces too many collisions. If newHasher is nil then a
+// default hasher is chosen as for NewMap. If newHasher considers keys equal
+// that the current hasher does not then only one of them is kept.
+func (m *Map[K, V]) Rehash(newHasher Hasher[K]) *Map[K, V] {
+	b := NewMapBuilderSized[K, V](newHasher, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		b.Set(k, v)
+	}
+	return b.Map()
+}
+
+// MapBuilder represents an efficient builder for creating Maps.
+// A MapBuilder is not safe for concurrent use.
+type MapBuilder[K comparable, V any] struct {
+	m        *Map[K, V] // current state
+	sizeHint int        // expected number of keys
+}
+
+// NewMapBuilder returns a new instance of MapBuilder.
 func NewMapBuilder[K comparable, V any](hasher Hasher[K]) *MapBuilder[K, V] {
 	return &MapBuilder[K, V]{m: NewMap[K, V](hasher)}
 }
 
+// NewMapBuilderSized returns a new instance of MapBuilder for a map that is
+// expected to hold about sizeHint keys.
+//
+// Map nodes are allocated as the trie grows so the hint cannot reserve space
+// for every key. Instead, if the hint is large enough, the root is created as
+// a full-width node rather than growing through the smaller node types. Most
+// allocations occur near the leaves so the savings are small.
+func NewMapBuilderSized[K comparable, V any](hasher Hasher[K], sizeHint int) *MapBuilder[K, V] {
+	return &MapBuilder[K, V]{m: NewMap[K, V](hasher), sizeHint: sizeHint}
+}
+
 // Map returns the underlying map. Only call once.
 // Builder is invalid after call. Will panic on second invocation.
 func (b *MapBuilder[K, V]) Map() *Map[K, V] {
-	assert(b.m != nil, "immutable.SortedMapBuilder.Map(): duplicate call to fetch map")
+	assert(b.m != nil, "immutable.MapBuilder.Map(): duplicate call to fetch map")
 	m := b.m
 	b.m = nil
 	return m
@@ -841,6 +1982,10 @@ func (b *MapBuilder[K, V]) Get(key K) (value V, ok bool) {
 // Set sets the value of the given key. See Map.Set() for additional details.
 func (b *MapBuilder[K, V]) Set(key K, value V) {
 	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
+	if b.m.root == nil && b.sizeHint > maxBitmapIndexedSize {
+		b.m.initHashArrayRoot(key, value)
+		return
+	}
 	b.m = b.m.set(key, value, true)
 }
 
@@ -858,9 +2003,63 @@ func (b *MapBuilder[K, V]) Iterator() *MapIterator[K, V] {
 
 // mapNode represents any node in the map tree.
 type mapNode[K comparable, V any] interface {
-	get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool)
-	set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V]
-	delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V]
+	get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool)
+	set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V]
+	delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V]
+	update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V]
+	mapValues(f func(key K, value V) V) mapNode[K, V]
+}
+
+// mapUpdateOp is the change to make to a key after calling a mapUpdateFunc.
+type mapUpdateOp int
+
+const (
+	mapUpdateNone   mapUpdateOp = iota // leave the key unchanged
+	mapUpdateSet                       // set the key to the returned value
+	mapUpdateDelete                    // remove the key, if it exists
+)
+
+// mapUpdateFunc is called by mapNode.update with the current value of a key
+// and whether it exists. It returns the new value and the change to make.
+type mapUpdateFunc[V any] func(old V, existed bool) (V, mapUpdateOp)
+
+// updateMissing calls f for a key that does not exist under n. Returns n with
+// the key set if f returns mapUpdateSet. Otherwise returns n unchanged.
+func updateMissing[K comparable, V any](n mapNode[K, V], key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	var zero V
+	value, op := f(zero, false)
+	if op != mapUpdateSet {
+		return n
+	}
+	var resized bool
+	return n.set(key, value, shift, keyHash, h, nil, &resized)
+}
+
+// updateEntry calls f for a key stored in a leaf node at the given value and
+// applies the change. Returns n if nothing changes.
+func updateEntry[K comparable, V any](n mapNode[K, V], key K, old V, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	value, op := f(old, true)
+	var resized bool
+	switch op {
+	case mapUpdateSet:
+		return n.set(key, value, shift, keyHash, h, nil, &resized)
+	case mapUpdateDelete:
+		return n.delete(key, shift, keyHash, h, nil, &resized)
+	}
+	return n
+}
+
+// mapEdit identifies the builder that owns a map node. A builder updates the
+// nodes it owns in-place and copies any other node before updating it, so
+// nodes shared with other maps are never changed. A nil owner is used for
+// immutable updates, which always copy.
+type mapEdit struct {
+	_ byte // non-zero size so each owner has a distinct address
+}
+
+// owns returns true if e may update a node owned by owner in-place.
+func (e *mapEdit) owns(owner *mapEdit) bool {
+	return e != nil && e == owner
 }
 
 var _ mapNode[string, any] = (*mapArrayNode[string, any])(nil)
@@ -882,6 +2081,7 @@ var _ mapLeafNode[string, any] = (*mapHashCollisionNode[string, any])(nil)
 // Entries are stored in insertion order. An array node expands into a bitmap
 // indexed node once a given threshold size is crossed.
 type mapArrayNode[K comparable, V any] struct {
+	edit    *mapEdit // owner allowed to update in-place
 	entries []mapEntry[K, V]
 }
 
@@ -895,18 +2095,18 @@ func (n *mapArrayNode[K, V]) indexOf(key K, h Hasher[K]) int {
 	return -1
 }
 
-// get returns the value for the given key.
-func (n *mapArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool) {
+// get returns the stored key and value for the given key.
+func (n *mapArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool) {
 	i := n.indexOf(key, h)
 	if i == -1 {
-		return value, false
+		return storedKey, value, false
 	}
-	return n.entries[i].value, true
+	return n.entries[i].key, n.entries[i].value, true
 }
 
 // set inserts or updates the value for a given key. If the key is inserted and
 // the new size crosses the max size threshold, a bitmap indexed node is returned.
-func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Mark as resized if the key doesn't exist.
@@ -917,15 +2117,15 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 	// If we are adding and it crosses the max size threshold, expand the node.
 	// We do this by continually setting the entries to a value node and expanding.
 	if idx == -1 && len(n.entries) >= maxArrayMapSize {
-		var node mapNode[K, V] = newMapValueNode(h.Hash(key), key, value)
+		var node mapNode[K, V] = newMapValueNode(edit, h.Hash(key), key, value)
 		for _, entry := range n.entries {
-			node = node.set(entry.key, entry.value, 0, h.Hash(entry.key), h, false, resized)
+			node = node.set(entry.key, entry.value, 0, h.Hash(entry.key), h, edit, resized)
 		}
 		return node
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if edit.owns(n.edit) {
 		if idx != -1 {
 			n.entries[idx] = mapEntry[K, V]{key, value}
 		} else {
@@ -936,7 +2136,7 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 
 	// Update existing entry if a match is found.
 	// Otherwise append to the end of the element list if it doesn't exist.
-	var other mapArrayNode[K, V]
+	other := mapArrayNode[K, V]{edit: edit}
 	if idx != -1 {
 		other.entries = make([]mapEntry[K, V], len(n.entries))
 		copy(other.entries, n.entries)
@@ -951,7 +2151,7 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 
 // delete removes the given key from the node. Returns the same node if key does
 // not exist. Returns a nil node when removing the last entry.
-func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Return original node if key does not exist.
@@ -965,8 +2165,8 @@ func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 		return nil
 	}
 
-	// Update in-place, if mutable.
-	if mutable {
+	// Update in-place, if owned.
+	if edit.owns(n.edit) {
 		copy(n.entries[idx:], n.entries[idx+1:])
 		n.entries[len(n.entries)-1] = mapEntry[K, V]{}
 		n.entries = n.entries[:len(n.entries)-1]
@@ -974,25 +2174,41 @@ func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 	}
 
 	// Otherwise create a copy with the given entry removed.
-	other := &mapArrayNode[K, V]{entries: make([]mapEntry[K, V], len(n.entries)-1)}
+	other := &mapArrayNode[K, V]{edit: edit, entries: make([]mapEntry[K, V], len(n.entries)-1)}
 	copy(other.entries[:idx], n.entries[:idx])
 	copy(other.entries[idx:], n.entries[idx+1:])
 	return other
 }
 
+// update calls f with the value of key and returns the node with the change
+// applied. Returns the same node if nothing changes.
+func (n *mapArrayNode[K, V]) update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	idx := n.indexOf(key, h)
+	if idx == -1 {
+		return updateMissing[K, V](n, key, shift, keyHash, h, f)
+	}
+	return updateEntry[K, V](n, key, n.entries[idx].value, shift, keyHash, h, f)
+}
+
+// mapValues returns a copy of the node with f applied to every value.
+func (n *mapArrayNode[K, V]) mapValues(f func(key K, value V) V) mapNode[K, V] {
+	return &mapArrayNode[K, V]{entries: mapEntryValues(n.entries, f)}
+}
+
 // mapBitmapIndexedNode represents a map branch node with a variable number of
 // node slots and indexed using a bitmap. Indexes for the node slots are
 // calculated by counting the number of set bits before the target bit using popcount.
 type mapBitmapIndexedNode[K comparable, V any] struct {
+	edit   *mapEdit // owner allowed to update in-place
 	bitmap uint32
 	nodes  []mapNode[K, V]
 }
 
-// get returns the value for the given key.
-func (n *mapBitmapIndexedNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool) {
+// get returns the stored key and value for the given key.
+func (n *mapBitmapIndexedNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool) {
 	bit := uint32(1) << ((keyHash >> shift) & mapNodeMask)
 	if (n.bitmap & bit) == 0 {
-		return value, false
+		return storedKey, value, false
 	}
 	child := n.nodes[bits.OnesCount32(n.bitmap&(bit-1))]
 	return child.get(key, shift+mapNodeBits, keyHash, h)
@@ -1000,7 +2216,7 @@ func (n *mapBitmapIndexedNode[K, V]) get(key K, shift uint, keyHash uint32, h Ha
 
 // set inserts or updates the value for the given key. If a new key is inserted
 // and the size crosses the max size threshold then a hash array node is returned.
-func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	// Extract the index for the bit segment of the key hash.
 	keyHashFrag := (keyHash >> shift) & mapNodeMask
 
@@ -1020,15 +2236,15 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 	// If the node doesn't exist then create a simple value leaf node.
 	var newNode mapNode[K, V]
 	if exists {
-		newNode = n.nodes[idx].set(key, value, shift+mapNodeBits, keyHash, h, mutable, resized)
+		newNode = n.nodes[idx].set(key, value, shift+mapNodeBits, keyHash, h, edit, resized)
 	} else {
-		newNode = newMapValueNode[K, V](keyHash, key, value)
+		newNode = newMapValueNode[K, V](edit, keyHash, key, value)
 	}
 
 	// Convert to a hash-array node once we exceed the max bitmap size.
 	// Copy each node based on their bit position within the bitmap.
 	if !exists && len(n.nodes) > maxBitmapIndexedSize {
-		var other mapHashArrayNode[K, V]
+		other := mapHashArrayNode[K, V]{edit: edit}
 		for i := uint(0); i < uint(len(other.nodes)); i++ {
 			if n.bitmap&(uint32(1)<<i) != 0 {
 				other.nodes[i] = n.nodes[other.count]
@@ -1040,8 +2256,8 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 		return &other
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if edit.owns(n.edit) {
 		if exists {
 			n.nodes[idx] = newNode
 		} else {
@@ -1055,7 +2271,7 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 
 	// If node exists at given slot then overwrite it with new node.
 	// Otherwise expand the node list and insert new node into appropriate position.
-	other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap | bit}
+	other := &mapBitmapIndexedNode[K, V]{edit: edit, bitmap: n.bitmap | bit}
 	if exists {
 		other.nodes = make([]mapNode[K, V], len(n.nodes))
 		copy(other.nodes, n.nodes)
@@ -1072,7 +2288,7 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 // delete removes the key from the tree. If the key does not exist then the
 // original node is returned. If removing the last child node then a nil is
 // returned. Note that shrinking the node will not convert it to an array node.
-func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	bit := uint32(1) << ((keyHash >> shift) & mapNodeMask)
 
 	// Return original node if key does not exist.
@@ -1085,22 +2301,48 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 
 	// Delegate delete to child node.
 	child := n.nodes[idx]
-	newChild := child.delete(key, shift+mapNodeBits, keyHash, h, mutable, resized)
+	newChild := child.delete(key, shift+mapNodeBits, keyHash, h, edit, resized)
 
 	// Return original node if key doesn't exist in child.
 	if !*resized {
 		return n
 	}
+	return n.replaceChild(bit, idx, newChild, edit)
+}
+
+// update calls f with the value of key and returns the node with the change
+// applied. Returns the same node if nothing changes.
+func (n *mapBitmapIndexedNode[K, V]) update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	bit := uint32(1) << ((keyHash >> shift) & mapNodeMask)
+
+	// Insert into this node if there is no child for the key.
+	if (n.bitmap & bit) == 0 {
+		return updateMissing[K, V](n, key, shift, keyHash, h, f)
+	}
 
+	// Delegate update to child node and return original node if unchanged.
+	idx := bits.OnesCount32(n.bitmap & (bit - 1))
+	child := n.nodes[idx]
+	newChild := child.update(key, shift+mapNodeBits, keyHash, h, f)
+	if newChild == child {
+		return n
+	}
+	return n.replaceChild(bit, idx, newChild, nil)
+}
+
+// replaceChild returns a node with the child for bit at index idx replaced by
+// child. If child is nil then it is removed, and if no children remain then
+// nil is returned.
+func (n *mapBitmapIndexedNode[K, V]) replaceChild(bit uint32, idx int, child mapNode[K, V], edit *mapEdit) mapNode[K, V] {
 	// Remove if returned child has been deleted.
-	if newChild == nil {
+	if child == nil {
 		// If we won't have any children then return nil.
 		if len(n.nodes) == 1 {
 			return nil
 		}
 
-		// Update in-place if mutable.
-		if mutable {
+		// Update in-place if owned.
+		if edit.owns(n.edit) {
 			n.bitmap ^= bit
 			copy(n.nodes[idx:], n.nodes[idx+1:])
 			n.nodes[len(n.nodes)-1] = nil
@@ -1109,7 +2351,7 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 		}
 
 		// Return copy with bit removed from bitmap and node removed from node list.
-		other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap ^ bit, nodes: make([]mapNode[K, V], len(n.nodes)-1)}
+		other := &mapBitmapIndexedNode[K, V]{edit: edit, bitmap: n.bitmap ^ bit, nodes: make([]mapNode[K, V], len(n.nodes)-1)}
 		copy(other.nodes[:idx], n.nodes[:idx])
 		copy(other.nodes[idx:], n.nodes[idx+1:])
 		return other
@@ -1117,40 +2359,51 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap, nodes: make([]mapNode[K, V], len(n.nodes))}
+	if !edit.owns(n.edit) {
+		other = &mapBitmapIndexedNode[K, V]{edit: edit, bitmap: n.bitmap, nodes: make([]mapNode[K, V], len(n.nodes))}
 		copy(other.nodes, n.nodes)
 	}
 
 	// Update child.
-	other.nodes[idx] = newChild
+	other.nodes[idx] = child
+	return other
+}
+
+// mapValues returns a copy of the node with f applied to every value.
+func (n *mapBitmapIndexedNode[K, V]) mapValues(f func(key K, value V) V) mapNode[K, V] {
+	other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap, nodes: make([]mapNode[K, V], len(n.nodes))}
+	for i, child := range n.nodes {
+		other.nodes[i] = child.mapValues(f)
+	}
 	return other
 }
 
 // mapHashArrayNode is a map branch node that stores nodes in a fixed length
 // array. Child nodes are indexed by their index bit segment for the current depth.
 type mapHashArrayNode[K comparable, V any] struct {
+	edit  *mapEdit                   // owner allowed to update in-place
 	count uint                       // number of set nodes
 	nodes [mapNodeSize]mapNode[K, V] // child node slots, may contain empties
 }
 
-// clone returns a shallow copy of n.
-func (n *mapHashArrayNode[K, V]) clone() *mapHashArrayNode[K, V] {
+// clone returns a shallow copy of n owned by edit.
+func (n *mapHashArrayNode[K, V]) clone(edit *mapEdit) *mapHashArrayNode[K, V] {
 	other := *n
+	other.edit = edit
 	return &other
 }
 
-// get returns the value for the given key.
-func (n *mapHashArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool) {
+// get returns the stored key and value for the given key.
+func (n *mapHashArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool) {
 	node := n.nodes[(keyHash>>shift)&mapNodeMask]
 	if node == nil {
-		return value, false
+		return storedKey, value, false
 	}
 	return node.get(key, shift+mapNodeBits, keyHash, h)
 }
 
 // set returns a node with the value set for the given key.
-func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	idx := (keyHash >> shift) & mapNodeMask
 	node := n.nodes[idx]
 
@@ -1159,15 +2412,15 @@ func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32,
 	var newNode mapNode[K, V]
 	if node == nil {
 		*resized = true
-		newNode = newMapValueNode(keyHash, key, value)
+		newNode = newMapValueNode(edit, keyHash, key, value)
 	} else {
-		newNode = node.set(key, value, shift+mapNodeBits, keyHash, h, mutable, resized)
+		newNode = node.set(key, value, shift+mapNodeBits, keyHash, h, edit, resized)
 	}
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = n.clone()
+	if !edit.owns(n.edit) {
+		other = n.clone(edit)
 	}
 
 	// Update child node (and update size, if new).
@@ -1181,7 +2434,7 @@ func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32,
 // delete returns a node with the given key removed. Returns the same node if
 // the key does not exist. If node shrinks to within bitmap-indexed size then
 // converts to a bitmap-indexed node.
-func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	idx := (keyHash >> shift) & mapNodeMask
 	node := n.nodes[idx]
 
@@ -1191,14 +2444,45 @@ func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Has
 	}
 
 	// Return original node if child is unchanged.
-	newNode := node.delete(key, shift+mapNodeBits, keyHash, h, mutable, resized)
+	newNode := node.delete(key, shift+mapNodeBits, keyHash, h, edit, resized)
 	if !*resized {
 		return n
 	}
+	return n.replaceChild(idx, newNode, edit)
+}
+
+// update calls f with the value of key and returns the node with the change
+// applied. Returns the same node if nothing changes.
+func (n *mapHashArrayNode[K, V]) update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	idx := (keyHash >> shift) & mapNodeMask
+	node := n.nodes[idx]
+
+	// Insert into this node if there is no child for the key.
+	if node == nil {
+		return updateMissing[K, V](n, key, shift, keyHash, h, f)
+	}
+
+	// Delegate update to child node and return original node if unchanged.
+	newNode := node.update(key, shift+mapNodeBits, keyHash, h, f)
+	if newNode == node {
+		return n
+	}
+	return n.replaceChild(idx, newNode, nil)
+}
+
+// replaceChild returns a node with the child at idx replaced by newNode. If
+// newNode is nil then the child is removed and the node may shrink to a
+// bitmap-indexed node, or to nil if no children remain.
+func (n *mapHashArrayNode[K, V]) replaceChild(idx uint32, newNode mapNode[K, V], edit *mapEdit) mapNode[K, V] {
+	// If we remove the last child then remove this node. This only occurs for
+	// nodes created with few children, such as the root of a sized builder.
+	if newNode == nil && n.count == 1 {
+		return nil
+	}
 
 	// If we remove a node and drop below a threshold, convert back to bitmap indexed node.
 	if newNode == nil && n.count <= maxBitmapIndexedSize {
-		other := &mapBitmapIndexedNode[K, V]{nodes: make([]mapNode[K, V], 0, n.count-1)}
+		other := &mapBitmapIndexedNode[K, V]{edit: edit, nodes: make([]mapNode[K, V], 0, n.count-1)}
 		for i, child := range n.nodes {
 			if child != nil && uint32(i) != idx {
 				other.bitmap |= 1 << uint(i)
@@ -1210,8 +2494,8 @@ func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Has
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = n.clone()
+	if !edit.owns(n.edit) {
+		other = n.clone(edit)
 	}
 
 	// Return copy of node with child updated.
@@ -1222,18 +2506,31 @@ func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Has
 	return other
 }
 
+// mapValues returns a copy of the node with f applied to every value.
+func (n *mapHashArrayNode[K, V]) mapValues(f func(key K, value V) V) mapNode[K, V] {
+	other := &mapHashArrayNode[K, V]{count: n.count}
+	for i, child := range n.nodes {
+		if child != nil {
+			other.nodes[i] = child.mapValues(f)
+		}
+	}
+	return other
+}
+
 // mapValueNode represents a leaf node with a single key/value pair.
 // A value node can be converted to a hash collision leaf node if a different
 // key with the same keyHash is inserted.
 type mapValueNode[K comparable, V any] struct {
+	edit    *mapEdit // owner allowed to update in-place
 	keyHash uint32
 	key     K
 	value   V
 }
 
-// newMapValueNode returns a new instance of mapValueNode.
-func newMapValueNode[K comparable, V any](keyHash uint32, key K, value V) *mapValueNode[K, V] {
+// newMapValueNode returns a new instance of mapValueNode owned by edit.
+func newMapValueNode[K comparable, V any](edit *mapEdit, keyHash uint32, key K, value V) *mapValueNode[K, V] {
 	return &mapValueNode[K, V]{
+		edit:    edit,
 		keyHash: keyHash,
 		key:     key,
 		value:   value,
@@ -1245,46 +2542,46 @@ func (n *mapValueNode[K, V]) keyHashValue() uint32 {
 	return n.keyHash
 }
 
-// get returns the value for the given key.
-func (n *mapValueNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool) {
+// get returns the stored key and value for the given key.
+func (n *mapValueNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool) {
 	if !h.Equal(n.key, key) {
-		return value, false
+		return storedKey, value, false
 	}
-	return n.value, true
+	return n.key, n.value, true
 }
 
 // set returns a new node with the new value set for the key. If the key equals
 // the node's key then a new value node is returned. If key is not equal to the
 // node's key but has the same hash then a hash collision node is returned.
 // Otherwise the nodes are merged into a branch node.
-func (n *mapValueNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapValueNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	// If the keys match then return a new value node overwriting the value.
 	if h.Equal(n.key, key) {
-		// Update in-place if mutable.
-		if mutable {
-			n.value = value
+		// Update in-place if owned.
+		if edit.owns(n.edit) {
+			n.key, n.value = key, value
 			return n
 		}
 		// Otherwise return a new copy.
-		return newMapValueNode(n.keyHash, key, value)
+		return newMapValueNode(edit, n.keyHash, key, value)
 	}
 
 	*resized = true
 
 	// Recursively merge nodes together if key hashes are different.
 	if n.keyHash != keyHash {
-		return mergeIntoNode[K, V](n, shift, keyHash, key, value)
+		return mergeIntoNode[K, V](edit, n, shift, keyHash, key, value)
 	}
 
 	// Merge into collision node if hash matches.
-	return &mapHashCollisionNode[K, V]{keyHash: keyHash, entries: []mapEntry[K, V]{
+	return &mapHashCollisionNode[K, V]{edit: edit, keyHash: keyHash, entries: []mapEntry[K, V]{
 		{key: n.key, value: n.value},
 		{key: key, value: value},
 	}}
 }
 
 // delete returns nil if the key matches the node's key. Otherwise returns the original node.
-func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	// Return original node if the keys do not match.
 	if !h.Equal(n.key, key) {
 		return n
@@ -1295,10 +2592,25 @@ func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 	return nil
 }
 
+// update calls f with the value of key and returns the node with the change
+// applied. Returns the same node if nothing changes.
+func (n *mapValueNode[K, V]) update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	if !h.Equal(n.key, key) {
+		return updateMissing[K, V](n, key, shift, keyHash, h, f)
+	}
+	return updateEntry[K, V](n, key, n.value, shift, keyHash, h, f)
+}
+
+// mapValues returns a copy of the node with f applied to the value.
+func (n *mapValueNode[K, V]) mapValues(f func(key K, value V) V) mapNode[K, V] {
+	return newMapValueNode(nil, n.keyHash, n.key, f(n.key, n.value))
+}
+
 // mapHashCollisionNode represents a leaf node that contains two or more key/value
 // pairs with the same key hash. Single pairs for a hash are stored as value nodes.
 type mapHashCollisionNode[K comparable, V any] struct {
-	keyHash uint32 // key hash for all entries
+	edit    *mapEdit // owner allowed to update in-place
+	keyHash uint32   // key hash for all entries
 	entries []mapEntry[K, V]
 }
 
@@ -1318,26 +2630,26 @@ func (n *mapHashCollisionNode[K, V]) indexOf(key K, h Hasher[K]) int {
 	return -1
 }
 
-// get returns the value for the given key.
-func (n *mapHashCollisionNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool) {
+// get returns the stored key and value for the given key.
+func (n *mapHashCollisionNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K]) (storedKey K, value V, ok bool) {
 	for i := range n.entries {
 		if h.Equal(n.entries[i].key, key) {
-			return n.entries[i].value, true
+			return n.entries[i].key, n.entries[i].value, true
 		}
 	}
-	return value, false
+	return storedKey, value, false
 }
 
 // set returns a copy of the node with key set to the given value.
-func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	// Merge node with key/value pair if this is not a hash collision.
 	if n.keyHash != keyHash {
 		*resized = true
-		return mergeIntoNode[K, V](n, shift, keyHash, key, value)
+		return mergeIntoNode[K, V](edit, n, shift, keyHash, key, value)
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if edit.owns(n.edit) {
 		if idx := n.indexOf(key, h); idx == -1 {
 			*resized = true
 			n.entries = append(n.entries, mapEntry[K, V]{key, value})
@@ -1349,7 +2661,7 @@ func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uin
 
 	// Append to end of node if key doesn't exist & mark resized.
 	// Otherwise copy nodes and overwrite at matching key index.
-	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash}
+	other := &mapHashCollisionNode[K, V]{edit: edit, keyHash: n.keyHash}
 	if idx := n.indexOf(key, h); idx == -1 {
 		*resized = true
 		other.entries = make([]mapEntry[K, V], len(n.entries)+1)
@@ -1366,7 +2678,7 @@ func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uin
 // delete returns a node with the given key deleted. Returns the same node if
 // the key does not exist. If removing the key would shrink the node to a single
 // entry then a value node is returned.
-func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], edit *mapEdit, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Return original node if key is not found.
@@ -1380,14 +2692,15 @@ func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 	// Convert to value node if we move to one entry.
 	if len(n.entries) == 2 {
 		return &mapValueNode[K, V]{
+			edit:    edit,
 			keyHash: n.keyHash,
 			key:     n.entries[idx^1].key,
 			value:   n.entries[idx^1].value,
 		}
 	}
 
-	// Remove entry in-place if mutable.
-	if mutable {
+	// Remove entry in-place if owned.
+	if edit.owns(n.edit) {
 		copy(n.entries[idx:], n.entries[idx+1:])
 		n.entries[len(n.entries)-1] = mapEntry[K, V]{}
 		n.entries = n.entries[:len(n.entries)-1]
@@ -1395,24 +2708,42 @@ func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 	}
 
 	// Return copy without entry if immutable.
-	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash, entries: make([]mapEntry[K, V], len(n.entries)-1)}
+	other := &mapHashCollisionNode[K, V]{edit: edit, keyHash: n.keyHash, entries: make([]mapEntry[K, V], len(n.entries)-1)}
 	copy(other.entries[:idx], n.entries[:idx])
 	copy(other.entries[idx:], n.entries[idx+1:])
 	return other
 }
 
-// mergeIntoNode merges a key/value pair into an existing node.
-// Caller must verify that node's keyHash is not equal to keyHash.
-func mergeIntoNode[K comparable, V any](node mapLeafNode[K, V], shift uint, keyHash uint32, key K, value V) mapNode[K, V] {
+// update calls f with the value of key and returns the node with the change
+// applied. Returns the same node if nothing changes.
+func (n *mapHashCollisionNode[K, V]) update(key K, shift uint, keyHash uint32, h Hasher[K], f mapUpdateFunc[V]) mapNode[K, V] {
+	idx := -1
+	if n.keyHash == keyHash {
+		idx = n.indexOf(key, h)
+	}
+	if idx == -1 {
+		return updateMissing[K, V](n, key, shift, keyHash, h, f)
+	}
+	return updateEntry[K, V](n, key, n.entries[idx].value, shift, keyHash, h, f)
+}
+
+// mapValues returns a copy of the node with f applied to every value.
+func (n *mapHashCollisionNode[K, V]) mapValues(f func(key K, value V) V) mapNode[K, V] {
+	return &mapHashCollisionNode[K, V]{keyHash: n.keyHash, entries: mapEntryValues(n.entries, f)}
+}
+
+// mergeIntoNode merges a key/value pair into an existing node. New nodes are
+// owned by edit. Caller must verify that node's keyHash is not equal to keyHash.
+func mergeIntoNode[K comparable, V any](edit *mapEdit, node mapLeafNode[K, V], shift uint, keyHash uint32, key K, value V) mapNode[K, V] {
 	idx1 := (node.keyHashValue() >> shift) & mapNodeMask
 	idx2 := (keyHash >> shift) & mapNodeMask
 
 	// Recursively build branch nodes to combine the node and its key.
-	other := &mapBitmapIndexedNode[K, V]{bitmap: (1 << idx1) | (1 << idx2)}
+	other := &mapBitmapIndexedNode[K, V]{edit: edit, bitmap: (1 << idx1) | (1 << idx2)}
 	if idx1 == idx2 {
-		other.nodes = []mapNode[K, V]{mergeIntoNode(node, shift+mapNodeBits, keyHash, key, value)}
+		other.nodes = []mapNode[K, V]{mergeIntoNode(edit, node, shift+mapNodeBits, keyHash, key, value)}
 	} else {
-		if newNode := newMapValueNode(keyHash, key, value); idx1 < idx2 {
+		if newNode := newMapValueNode(edit, keyHash, key, value); idx1 < idx2 {
 			other.nodes = []mapNode[K, V]{node, newNode}
 		} else {
 			other.nodes = []mapNode[K, V]{newNode, node}
@@ -1427,6 +2758,15 @@ type mapEntry[K comparable, V any] struct {
 	value V
 }
 
+// mapEntryValues returns a copy of entries with f applied to every value.
+func mapEntryValues[K comparable, V any](entries []mapEntry[K, V], f func(key K, value V) V) []mapEntry[K, V] {
+	other := make([]mapEntry[K, V], len(entries))
+	for i, entry := range entries {
+		other[i] = mapEntry[K, V]{key: entry.key, value: f(entry.key, entry.value)}
+	}
+	return other
+}
+
 // MapIterator represents an iterator over a map's key/value pairs. Although
 // map keys are not sorted, the iterator's order is deterministic.
 type MapIterator[K comparable, V any] struct {
@@ -1444,7 +2784,7 @@ func (itr *MapIterator[K, V]) Done() bool {
 // First resets the iterator to the first key/value pair.
 func (itr *MapIterator[K, V]) First() {
 	// Exit immediately if the map is empty.
-	if itr.m.root == nil {
+	if itr.m == nil || itr.m.root == nil {
 		itr.depth = -1
 		return
 	}
@@ -1455,6 +2795,76 @@ func (itr *MapIterator[K, V]) First() {
 	itr.first()
 }
 
+// Last moves the iterator to the last key/value pair, which is the last pair
+// that Next would return from First.
+func (itr *MapIterator[K, V]) Last() {
+	// Exit immediately if the map is empty.
+	if itr.m == nil || itr.m.root == nil {
+		itr.depth = -1
+		return
+	}
+
+	// Initialize the stack to the right most element.
+	itr.stack[0] = mapIteratorElem[K, V]{node: itr.m.root}
+	itr.depth = 0
+	itr.last()
+}
+
+// Seek moves the iterator so that the next call to Next returns the pair for
+// key. The pairs that follow are the same as those following key when iterating
+// from First, so a saved key can be used to resume iteration. If key does not
+// exist then the iterator is done.
+func (itr *MapIterator[K, V]) Seek(key K) {
+	itr.depth = -1
+	if itr.m == nil || itr.m.root == nil {
+		return
+	}
+
+	// Walk down the path for the key hash, recording the index taken at each
+	// node so the stack matches the state reached by iterating to key.
+	h := itr.m.hasher
+	keyHash := h.Hash(key)
+	node := itr.m.root
+	for depth, shift := 0, uint(0); ; depth, shift = depth+1, shift+mapNodeBits {
+		elem := &itr.stack[depth]
+		elem.node = node
+
+		switch n := node.(type) {
+		case *mapArrayNode[K, V]:
+			if elem.index = n.indexOf(key, h); elem.index != -1 {
+				itr.depth = depth
+			}
+			return
+
+		case *mapBitmapIndexedNode[K, V]:
+			bit := uint32(1) << ((keyHash >> shift) & mapNodeMask)
+			if (n.bitmap & bit) == 0 {
+				return
+			}
+			elem.index = bits.OnesCount32(n.bitmap & (bit - 1))
+			node = n.nodes[elem.index]
+
+		case *mapHashArrayNode[K, V]:
+			elem.index = int((keyHash >> shift) & mapNodeMask)
+			if node = n.nodes[elem.index]; node == nil {
+				return
+			}
+
+		case *mapValueNode[K, V]:
+			if elem.index = 0; h.Equal(n.key, key) {
+				itr.depth = depth
+			}
+			return
+
+		case *mapHashCollisionNode[K, V]:
+			if elem.index = n.indexOf(key, h); elem.index != -1 {
+				itr.depth = depth
+			}
+			return
+		}
+	}
+}
+
 // Next returns the next key/value pair. Returns a nil key when no elements remain.
 func (itr *MapIterator[K, V]) Next() (key K, value V, ok bool) {
 	// Return nil key if iteration is done.
@@ -1525,6 +2935,76 @@ func (itr *MapIterator[K, V]) next() {
 	}
 }
 
+// Prev returns the current key/value pair and moves the iterator backward, in
+// the reverse of the order used by Next. Returns a nil key when no elements
+// remain.
+func (itr *MapIterator[K, V]) Prev() (key K, value V, ok bool) {
+	// Return nil key if iteration is done.
+	if itr.Done() {
+		return key, value, false
+	}
+
+	// Retrieve current index & value. Current node is always a leaf.
+	elem := &itr.stack[itr.depth]
+	switch node := elem.node.(type) {
+	case *mapArrayNode[K, V]:
+		entry := &node.entries[elem.index]
+		key, value = entry.key, entry.value
+	case *mapValueNode[K, V]:
+		key, value = node.key, node.value
+	case *mapHashCollisionNode[K, V]:
+		entry := &node.entries[elem.index]
+		key, value = entry.key, entry.value
+	}
+
+	itr.prev()
+	return key, value, true
+}
+
+// prev moves to the previous available key.
+func (itr *MapIterator[K, V]) prev() {
+	for ; itr.depth >= 0; itr.depth-- {
+		elem := &itr.stack[itr.depth]
+
+		switch node := elem.node.(type) {
+		case *mapArrayNode[K, V]:
+			if elem.index > 0 {
+				elem.index--
+				return
+			}
+
+		case *mapBitmapIndexedNode[K, V]:
+			if elem.index > 0 {
+				elem.index--
+				itr.stack[itr.depth+1].node = node.nodes[elem.index]
+				itr.depth++
+				itr.last()
+				return
+			}
+
+		case *mapHashArrayNode[K, V]:
+			for i := elem.index - 1; i >= 0; i-- {
+				if node.nodes[i] != nil {
+					elem.index = i
+					itr.stack[itr.depth+1].node = node.nodes[elem.index]
+					itr.depth++
+					itr.last()
+					return
+				}
+			}
+
+		case *mapValueNode[K, V]:
+			continue // always the first value, traverse up
+
+		case *mapHashCollisionNode[K, V]:
+			if elem.index > 0 {
+				elem.index--
+				return
+			}
+		}
+	}
+}
+
 // first positions the stack left most index.
 // Elements and indexes at and below the current depth are assumed to be correct.
 func (itr *MapIterator[K, V]) first() {
@@ -1545,7 +3025,42 @@ func (itr *MapIterator[K, V]) first() {
 				}
 			}
 
-		default: // *mapArrayNode, mapLeafNode
+		default: // *mapArrayNode, mapLeafNode
+			elem.index = 0
+			return
+		}
+	}
+}
+
+// last positions the stack right most index.
+// Elements and indexes at and below the current depth are assumed to be correct.
+func (itr *MapIterator[K, V]) last() {
+	for ; ; itr.depth++ {
+		elem := &itr.stack[itr.depth]
+
+		switch node := elem.node.(type) {
+		case *mapBitmapIndexedNode[K, V]:
+			elem.index = len(node.nodes) - 1
+			itr.stack[itr.depth+1].node = node.nodes[elem.index]
+
+		case *mapHashArrayNode[K, V]:
+			for i := len(node.nodes) - 1; i >= 0; i-- {
+				if node.nodes[i] != nil { // find last node
+					elem.index = i
+					itr.stack[itr.depth+1].node = node.nodes[i]
+					break
+				}
+			}
+
+		case *mapArrayNode[K, V]:
+			elem.index = len(node.entries) - 1
+			return
+
+		case *mapHashCollisionNode[K, V]:
+			elem.index = len(node.entries) - 1
+			return
+
+		default: // *mapValueNode
 			elem.index = 0
 			return
 		}
@@ -1558,6 +3073,33 @@ type mapIteratorElem[K comparable, V any] struct {
 	index int
 }
 
+// MapSortedIterator represents an iterator over a sorted snapshot of a map's
+// key/value pairs. It is created by Map.IteratorSorted.
+type MapSortedIterator[K comparable, V any] struct {
+	entries []mapEntry[K, V] // sorted pairs
+	index   int              // current position
+}
+
+// Done returns true if no more elements remain in the iterator.
+func (itr *MapSortedIterator[K, V]) Done() bool {
+	return itr.index >= len(itr.entries)
+}
+
+// First resets the iterator to the first key/value pair.
+func (itr *MapSortedIterator[K, V]) First() {
+	itr.index = 0
+}
+
+// Next returns the next key/value pair. Returns a nil key when no elements remain.
+func (itr *MapSortedIterator[K, V]) Next() (key K, value V, ok bool) {
+	if itr.Done() {
+		return key, value, false
+	}
+	entry := &itr.entries[itr.index]
+	itr.index++
+	return entry.key, entry.value, true
+}
+
 // Sorted map child node limit size.
 const (
 	sortedMapNodeSize = 32
@@ -1567,6 +3109,8 @@ const (
 // is determined by the Comparer used by the map.
 //
 // This map is implemented as a B+tree.
+//
+// As with Map, a nil *SortedMap is treated as an empty map by read methods.
 type SortedMap[K comparable, V any] struct {
 	size     int                 // total number of key/value pairs
 	root     sortedMapNode[K, V] // root of b+tree
@@ -1582,15 +3126,65 @@ func NewSortedMap[K comparable, V any](comparer Comparer[K]) *SortedMap[K, V] {
 	}
 }
 
+// NewSortedMapChecked returns a new SortedMap which verifies every comparison
+// made by comparer and panics if it is inconsistent. This is intended for
+// debugging comparers since an invalid comparer otherwise silently loses or
+// duplicates entries. Comparisons must return -1, 0, or 1 and must be
+// antisymmetric. After each insert the key is also compared with its
+// neighbours along the inserted path to check that the order is transitive.
+// If comparer is nil then the default comparer for K is checked.
+//
+// Comparers may return 0 for keys which are not ==, such as
+// CaseInsensitiveStringComparer, in which case the keys are treated as equal.
+//
+// Each comparison of a and b also compares b and a, and every insert makes
+// extra comparisons, so the checked map is noticeably slower than an
+// unchecked one.
+func NewSortedMapChecked[K comparable, V any](comparer Comparer[K]) *SortedMap[K, V] {
+	if comparer == nil {
+		var k K
+		comparer = NewComparer(k)
+	}
+	return NewSortedMap[K, V](&checkedComparer[K]{comparer: comparer})
+}
+
 // Len returns the number of elements in the sorted map.
 func (m *SortedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
 	return m.size
 }
 
+// Clone returns the map. Maps are immutable so no copy is required and the
+// result can be shared freely between goroutines.
+func (m *SortedMap[K, V]) Clone() *SortedMap[K, V] {
+	return m
+}
+
+// Clear returns an empty map that uses the same comparer as m. Returns the
+// original map if it is already empty.
+func (m *SortedMap[K, V]) Clear() *SortedMap[K, V] {
+	if m.Len() == 0 {
+		return m
+	}
+	return NewSortedMap[K, V](m.comparer)
+}
+
+// Comparer returns the comparer used by the map. If the map was created without
+// a comparer then this returns nil until a default comparer is chosen by the
+// first Set.
+func (m *SortedMap[K, V]) Comparer() Comparer[K] {
+	if m == nil {
+		return nil
+	}
+	return m.comparer
+}
+
 // Get returns the value for a given key and a flag indicating if the key is set.
 // The flag can be used to distinguish between a nil-set key versus an unset key.
 func (m *SortedMap[K, V]) Get(key K) (V, bool) {
-	if m.root == nil {
+	if m == nil || m.root == nil {
 		var v V
 		return v, false
 	}
@@ -1637,6 +3231,11 @@ func (m *SortedMap[K, V]) set(key K, value V, mutable bool) *SortedMap[K, V] {
 	if resized {
 		other.size++
 	}
+
+	// Verify the order around the key if created by NewSortedMapChecked.
+	if _, ok := comparer.(*checkedComparer[K]); ok {
+		checkSortedMapPath(newRoot, key, comparer)
+	}
 	return other
 }
 
@@ -1648,7 +3247,7 @@ func (m *SortedMap[K, V]) Delete(key K) *SortedMap[K, V] {
 
 func (m *SortedMap[K, V]) delete(key K, mutable bool) *SortedMap[K, V] {
 	// Return original map if no keys exist.
-	if m.root == nil {
+	if m == nil || m.root == nil {
 		return m
 	}
 
@@ -1684,7 +3283,194 @@ func (m *SortedMap[K, V]) Iterator() *SortedMapIterator[K, V] {
 	return itr
 }
 
+// RangeFunc calls f for each key/value pair with a key in the half-open range
+// [lo, hi), in ascending key order. Neither lo nor hi need to exist in the map.
+// Iteration stops early if f returns false.
+func (m *SortedMap[K, V]) RangeFunc(lo, hi K, f func(key K, value V) bool) {
+	if m == nil || m.root == nil {
+		return
+	}
+
+	itr := m.Iterator()
+	itr.Seek(lo)
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if m.comparer.Compare(k, hi) != -1 || !f(k, v) {
+			return
+		}
+	}
+}
+
+// Scan calls f for each key/value pair with a key greater than or equal to
+// from, in ascending key order. from does not need to exist in the map, in
+// which case scanning begins at the next greater key. Iteration stops early
+// if f returns false.
+func (m *SortedMap[K, V]) Scan(from K, f func(key K, value V) bool) {
+	itr := m.Iterator()
+	itr.Seek(from)
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// ScanReverse calls f for each key/value pair with a key less than or equal
+// to from, in descending key order. from does not need to exist in the map,
+// in which case scanning begins at the next lesser key. Iteration stops early
+// if f returns false.
+func (m *SortedMap[K, V]) ScanReverse(from K, f func(key K, value V) bool) {
+	itr := m.Iterator()
+	itr.Seek(from)
+	if itr.Done() {
+		// All keys are less than from so start from the last key.
+		itr.Last()
+	} else if k, v, _ := itr.Prev(); m.comparer.Compare(k, from) == 0 && !f(k, v) {
+		// Seek positions the iterator at the smallest key greater than or
+		// equal to from. Only include it if it is an exact match.
+		return
+	}
+
+	for !itr.Done() {
+		k, v, _ := itr.Prev()
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Keys returns a new slice containing the keys of the map in key order.
+func (m *SortedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, _, _ := itr.Next()
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a new slice containing the values of the map in key order.
+func (m *SortedMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		_, v, _ := itr.Next()
+		values = append(values, v)
+	}
+	return values
+}
+
+// Entries returns a list containing the key/value pairs of the map in key order.
+func (m *SortedMap[K, V]) Entries() *List[Entry[K, V]] {
+	b := NewListBuilder[Entry[K, V]]()
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		b.Append(Entry[K, V]{Key: k, Value: v})
+	}
+	return b.List()
+}
+
+// MinKey returns the lowest key in the map. Returns false if the map is empty.
+func (m *SortedMap[K, V]) MinKey() (key K, ok bool) {
+	itr := m.Iterator()
+	key, _, ok = itr.Next()
+	return key, ok
+}
+
+// MaxKey returns the highest key in the map. Returns false if the map is empty.
+func (m *SortedMap[K, V]) MaxKey() (key K, ok bool) {
+	itr := m.Iterator()
+	itr.Last()
+	key, _, ok = itr.Prev()
+	return key, ok
+}
+
+// Floor returns the key/value pair with the largest key less than or equal to
+// key. Returns false if no such key exists.
+func (m *SortedMap[K, V]) Floor(key K) (K, V, bool) {
+	itr := m.Iterator()
+	itr.Seek(key)
+	if itr.Done() {
+		// All keys are less than key so the floor is the last key, if any.
+		itr.Last()
+		return itr.Prev()
+	}
+
+	// The iterator is positioned at the smallest key greater than or equal to
+	// key. If it is not an exact match then the floor is the key before it.
+	k, v, ok := itr.Prev()
+	if m.comparer.Compare(k, key) == 0 {
+		return k, v, ok
+	}
+	return itr.Prev()
+}
+
+// Ceiling returns the key/value pair with the smallest key greater than or
+// equal to key. Returns false if no such key exists.
+func (m *SortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	itr := m.Iterator()
+	itr.Seek(key)
+	return itr.Next()
+}
+
+// Split returns a map of the keys less than key and a map of the keys greater
+// than or equal to key. The larger half is derived from m by deleting the keys
+// of the smaller half so it shares most of its structure with m.
+func (m *SortedMap[K, V]) Split(key K) (left, right *SortedMap[K, V]) {
+	if m == nil || m.root == nil {
+		return m, m
+	}
+
+	// Count the keys less than key, stopping once they are the larger half.
+	half := m.Len() / 2
+	var n int
+	itr := m.Iterator()
+	for !itr.Done() && n <= half {
+		if k, _, _ := itr.Next(); m.comparer.Compare(k, key) != -1 {
+			break
+		}
+		n++
+	}
+
+	b := NewSortedMapBuilder[K, V](m.comparer)
+	if n <= half {
+		right = m
+		for itr.First(); n > 0; n-- {
+			k, v, _ := itr.Next()
+			b.Set(k, v)
+			right = right.Delete(k)
+		}
+		return b.Map(), right
+	}
+
+	left = m
+	for itr.Seek(key); !itr.Done(); {
+		k, v, _ := itr.Next()
+		b.Set(k, v)
+		left = left.Delete(k)
+	}
+	return left, b.Map()
+}
+
+// String returns a string representation of the map in key order, such as
+// "SortedMap{k1:v1, k2:v2}". Only the first 100 pairs are included.
+func (m *SortedMap[K, V]) String() string {
+	w := newElemWriter("SortedMap")
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		if !w.write("%v:%v", k, v) {
+			break
+		}
+	}
+	return w.String()
+}
+
 // SortedMapBuilder represents an efficient builder for creating sorted maps.
+// A SortedMapBuilder is not safe for concurrent use.
 type SortedMapBuilder[K comparable, V any] struct {
 	m *SortedMap[K, V] // current state
 }
@@ -2029,7 +3815,7 @@ func (itr *SortedMapIterator[K, V]) Done() bool {
 
 // First moves the iterator to the first key/value pair.
 func (itr *SortedMapIterator[K, V]) First() {
-	if itr.m.root == nil {
+	if itr.m == nil || itr.m.root == nil {
 		itr.depth = -1
 		return
 	}
@@ -2040,7 +3826,7 @@ func (itr *SortedMapIterator[K, V]) First() {
 
 // Last moves the iterator to the last key/value pair.
 func (itr *SortedMapIterator[K, V]) Last() {
-	if itr.m.root == nil {
+	if itr.m == nil || itr.m.root == nil {
 		itr.depth = -1
 		return
 	}
@@ -2050,10 +3836,10 @@ func (itr *SortedMapIterator[K, V]) Last() {
 }
 
 // Seek moves the iterator position to the given key in the map.
-// If the key does not exist then the next key is used. If no more keys exist
-// then the iteartor is marked as done.
+// If the key does not exist then the next greater key is used. If no more keys
+// exist then the iterator is marked as done.
 func (itr *SortedMapIterator[K, V]) Seek(key K) {
-	if itr.m.root == nil {
+	if itr.m == nil || itr.m.root == nil {
 		itr.depth = -1
 		return
 	}
@@ -2217,18 +4003,24 @@ type Hasher[K comparable] interface {
 	Equal(a, b K) bool
 }
 
-// NewHasher returns the built-in hasher for a given key type.
+// NewHasher returns the built-in hasher for a given key type. Built-in hashers
+// exist for string, integer, floating-point, and bool keys as well as types
+// derived from them. Panics if no built-in hasher exists for the type.
+//
+// Built-in hashers treat every NaN as equal to every other NaN, unlike the ==
+// operator, so a map or set holds at most one NaN key and it can be found by
+// Get. This matches the ordering used by NewComparer.
 func NewHasher[K comparable](key K) Hasher[K] {
 	// Attempt to use non-reflection based hasher first.
 	switch (any(key)).(type) {
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, string:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64, bool, string:
 		return &defaultHasher[K]{}
 	}
 
 	// Fallback to reflection-based hasher otherwise.
 	// This is used when caller wraps a type around a primitive type.
 	switch reflect.TypeOf(key).Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.String:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
 		return &reflectHasher[K]{}
 	}
 
@@ -2256,6 +4048,10 @@ func (h *reflectHasher[K]) Hash(key K) uint32 {
 		return hashUint64(uint64(reflect.ValueOf(key).Int()))
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		return hashUint64(reflect.ValueOf(key).Uint())
+	case reflect.Float32, reflect.Float64:
+		return hashFloat64(reflect.ValueOf(key).Float())
+	case reflect.Bool:
+		return hashBool(reflect.ValueOf(key).Bool())
 	case reflect.String:
 		var hash uint32
 		s := reflect.ValueOf(key).String()
@@ -2268,13 +4064,18 @@ func (h *reflectHasher[K]) Hash(key K) uint32 {
 }
 
 // Equal returns true if a is equal to b. Otherwise returns false.
-// Panics if a and b are not int-ish or string-ish.
+// Panics if a and b are not int-ish, float-ish, bool-ish, or string-ish.
 func (h *reflectHasher[K]) Equal(a, b K) bool {
 	switch reflect.TypeOf(a).Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return reflect.ValueOf(a).Int() == reflect.ValueOf(b).Int()
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		return reflect.ValueOf(a).Uint() == reflect.ValueOf(b).Uint()
+	case reflect.Float32, reflect.Float64:
+		x, y := reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float()
+		return x == y || (x != x && y != y) // NaN is equal to NaN
+	case reflect.Bool:
+		return reflect.ValueOf(a).Bool() == reflect.ValueOf(b).Bool()
 	case reflect.String:
 		return reflect.ValueOf(a).String() == reflect.ValueOf(b).String()
 	}
@@ -2292,6 +4093,25 @@ func hashUint64(value uint64) uint32 {
 	return uint32(hash)
 }
 
+// hashFloat64 returns a 32-bit hash for a floating-point value.
+// Negative zero hashes the same as positive zero since they are equal.
+func hashFloat64(value float64) uint32 {
+	if value == 0 {
+		value = 0
+	} else if value != value {
+		value = math.NaN() // all NaN payloads hash the same
+	}
+	return fnvUint64(math.Float64bits(value))
+}
+
+// hashBool returns a 32-bit hash for a boolean value.
+func hashBool(value bool) uint32 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
 // defaultHasher implements Hasher.
 type defaultHasher[K comparable] struct{}
 
@@ -2321,18 +4141,252 @@ func (h *defaultHasher[K]) Hash(key K) uint32 {
 		return hashUint64(uint64(x))
 	case uintptr:
 		return hashUint64(uint64(x))
+	case float32:
+		return hashFloat64(float64(x))
+	case float64:
+		return hashFloat64(x)
+	case bool:
+		return hashBool(x)
 	case string:
 		return hashString(x)
 	}
 	panic(fmt.Sprintf("immutable.defaultHasher.Hash: must set comparer for %T type", key))
 }
 
-// Equal returns true if a is equal to b. Otherwise returns false.
-// Panics if a and b are not ints.
+// Equal returns true if a is equal to b. Otherwise returns false. NaN keys
+// are equal to each other.
 func (h *defaultHasher[K]) Equal(a, b K) bool {
+	return a == b || (a != a && b != b)
+}
+
+// Uint64Hasher implements Hasher for uint64 keys using a 32-bit FNV-1a hash of
+// the key's bytes. Distinct keys may produce the same hash, in which case the
+// map stores them in a collision node and distinguishes them using Equal.
+type Uint64Hasher struct{}
+
+// Hash returns a hash for key.
+func (h Uint64Hasher) Hash(key uint64) uint32 {
+	return fnvUint64(key)
+}
+
+// Equal returns true if a is equal to b.
+func (h Uint64Hasher) Equal(a, b uint64) bool {
+	return a == b
+}
+
+// Int64Hasher implements Hasher for int64 keys using a 32-bit FNV-1a hash of
+// the key's bytes. See Uint64Hasher for collision behavior.
+type Int64Hasher struct{}
+
+// Hash returns a hash for key.
+func (h Int64Hasher) Hash(key int64) uint32 {
+	return fnvUint64(uint64(key))
+}
+
+// Equal returns true if a is equal to b.
+func (h Int64Hasher) Equal(a, b int64) bool {
 	return a == b
 }
 
+// Float64Hasher implements Hasher for float64 keys using a 32-bit FNV-1a hash
+// of the key's IEEE 754 bits. Positive and negative zero hash to the same
+// value since they are equal. Every NaN hashes to the same value and is equal
+// to any other NaN, as with the default hasher, so a NaN key can be found by
+// Get. See Uint64Hasher for collision behavior.
+type Float64Hasher struct{}
+
+// Hash returns a hash for key.
+func (h Float64Hasher) Hash(key float64) uint32 {
+	return hashFloat64(key)
+}
+
+// Equal returns true if a is equal to b or both are NaN.
+func (h Float64Hasher) Equal(a, b float64) bool {
+	return a == b || (a != a && b != b)
+}
+
+// StringHasher implements Hasher for string keys using a 32-bit FNV-1a hash.
+//
+// Byte slices are not comparable so they cannot be used as map keys directly.
+// Instead, key the map by string(b) and use HashBytes to compute the hash of
+// a byte slice without converting it, which returns the same hash as Hash for
+// the same content.
+type StringHasher struct{}
+
+// Hash returns a hash for key.
+func (h StringHasher) Hash(key string) uint32 {
+	return fnvBytes(key)
+}
+
+// HashBytes returns the same hash as Hash(string(b)). A nil slice hashes the
+// same as an empty slice.
+func (h StringHasher) HashBytes(b []byte) uint32 {
+	return fnvBytes(b)
+}
+
+// Equal returns true if a is equal to b.
+func (h StringHasher) Equal(a, b string) bool {
+	return a == b
+}
+
+// CaseInsensitiveStringHasher implements Hasher for string keys that are
+// compared case-insensitively, so that "Foo" and "foo" are the same key.
+//
+// Equal uses strings.EqualFold. Hash is computed over the simple case folding
+// of each rune so that keys which are equal under EqualFold always hash the
+// same, including runes such as 'ſ' and the Kelvin sign that lowercasing alone
+// would not map together.
+type CaseInsensitiveStringHasher struct{}
+
+// Hash returns a hash for key.
+func (h CaseInsensitiveStringHasher) Hash(key string) uint32 {
+	hash := uint32(2166136261)
+	for _, r := range key {
+		// Runes fit in 21 bits so three bytes are enough.
+		r = foldRune(r)
+		for i := 0; i < 3; i++ {
+			hash ^= uint32(r & 0xff)
+			hash *= 16777619
+			r >>= 8
+		}
+	}
+	return hash
+}
+
+// Equal returns true if a is equal to b under Unicode case folding.
+func (h CaseInsensitiveStringHasher) Equal(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// foldRune returns the smallest rune that r is equivalent to under simple
+// case folding. Two runes are equal under strings.EqualFold if and only if
+// they fold to the same rune.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// fnvBytes returns the 32-bit FNV-1a hash of value.
+func fnvBytes[B string | []byte](value B) uint32 {
+	hash := uint32(2166136261)
+	for i := 0; i < len(value); i++ {
+		hash ^= uint32(value[i])
+		hash *= 16777619
+	}
+	return hash
+}
+
+// fnvUint64 returns the 32-bit FNV-1a hash of the little-endian bytes of value.
+func fnvUint64(value uint64) uint32 {
+	hash := uint32(2166136261)
+	for i := 0; i < 8; i++ {
+		hash ^= uint32(value & 0xff)
+		hash *= 16777619
+		value >>= 8
+	}
+	return hash
+}
+
+// NewSeededHasher returns a hasher which mixes seed into the hashes of keys.
+// If hasher is nil then the built-in hasher for K is used and, similar to
+// NewHasher, this panics if no built-in hasher exists for K.
+//
+// When using the built-in hasher, string and integer keys are hashed directly
+// with the seed so keys which collide under the built-in hasher are unlikely
+// to collide under the seeded hasher. Other keys are hashed with hasher and
+// the result is mixed with the seed. This spreads keys differently across the
+// map for each seed but keys with identical hashes from hasher still collide.
+func NewSeededHasher[K comparable](hasher Hasher[K], seed uint64) Hasher[K] {
+	if hasher == nil {
+		var key K
+		hasher = NewHasher(key)
+	}
+	_, builtin := hasher.(*defaultHasher[K])
+	return &seededHasher[K]{hasher: hasher, seed: seed, builtin: builtin}
+}
+
+// seededHasher wraps a hasher and mixes a seed into each hash.
+type seededHasher[K comparable] struct {
+	hasher  Hasher[K]
+	seed    uint64
+	builtin bool // true if hasher is the built-in hasher for K
+}
+
+// Hash returns a hash for key.
+func (h *seededHasher[K]) Hash(key K) uint32 {
+	// Custom hashers may treat distinct values as equal so only the built-in
+	// hasher can be bypassed.
+	if !h.builtin {
+		return mixHash(uint64(h.hasher.Hash(key)) ^ h.seed)
+	}
+
+	switch x := (any(key)).(type) {
+	case string:
+		return hashStringSeed(x, h.seed)
+	case int:
+		return mixHash(uint64(x) ^ h.seed)
+	case int8:
+		return mixHash(uint64(x) ^ h.seed)
+	case int16:
+		return mixHash(uint64(x) ^ h.seed)
+	case int32:
+		return mixHash(uint64(x) ^ h.seed)
+	case int64:
+		return mixHash(uint64(x) ^ h.seed)
+	case uint:
+		return mixHash(uint64(x) ^ h.seed)
+	case uint8:
+		return mixHash(uint64(x) ^ h.seed)
+	case uint16:
+		return mixHash(uint64(x) ^ h.seed)
+	case uint32:
+		return mixHash(uint64(x) ^ h.seed)
+	case uint64:
+		return mixHash(x ^ h.seed)
+	case uintptr:
+		return mixHash(uint64(x) ^ h.seed)
+	}
+	return mixHash(uint64(h.hasher.Hash(key)) ^ h.seed)
+}
+
+// Equal returns true if a is equal to b.
+func (h *seededHasher[K]) Equal(a, b K) bool {
+	return h.hasher.Equal(a, b)
+}
+
+// hashStringSeed returns a hash of value using a 64-bit FNV-1a hash with an
+// offset basis derived from seed.
+func hashStringSeed(value string, seed uint64) uint32 {
+	hash := uint64(14695981039346656037) ^ seed
+	for i := 0; i < len(value); i++ {
+		hash ^= uint64(value[i])
+		hash *= 1099511628211
+	}
+	return mixHash(hash ^ uint64(len(value)))
+}
+
+// mixHash scrambles the bits of value using the MurmurHash3 finalizer and
+// folds the result to 32 bits.
+func mixHash(value uint64) uint32 {
+	value ^= value >> 33
+	value *= 0xff51afd7ed558ccd
+	value ^= value >> 33
+	value *= 0xc4ceb9fe1a85ec53
+	value ^= value >> 33
+	return uint32(value ^ value>>32)
+}
+
 // Comparer allows the comparison of two keys for the purpose of sorting.
 type Comparer[K comparable] interface {
 	// Returns -1 if a is less than b, returns 1 if a is greater than b,
@@ -2341,18 +4395,18 @@ type Comparer[K comparable] interface {
 }
 
 // NewComparer returns the built-in comparer for a given key type.
-// Note that only int-ish and string-ish types are supported, despite the 'comparable' constraint.
+// Note that only int-ish, float-ish, and string-ish types are supported, despite the 'comparable' constraint.
 // Attempts to use other types will result in a panic - users should define their own Comparers for these cases.
 func NewComparer[K comparable](key K) Comparer[K] {
 	// Attempt to use non-reflection based comparer first.
 	switch (any(key)).(type) {
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, string:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64, string:
 		return &defaultComparer[K]{}
 	}
 	// Fallback to reflection-based comparer otherwise.
 	// This is used when caller wraps a type around a primitive type.
 	switch reflect.TypeOf(key).Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.String:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
 		return &reflectComparer[K]{}
 	}
 	// If no comparers match then panic.
@@ -2389,20 +4443,154 @@ func (c *defaultComparer[K]) Compare(i K, j K) int {
 		return defaultCompare(x, (any(j)).(uint64))
 	case uintptr:
 		return defaultCompare(x, (any(j)).(uintptr))
+	case float32:
+		return defaultCompare(x, (any(j)).(float32))
+	case float64:
+		return defaultCompare(x, (any(j)).(float64))
 	case string:
 		return defaultCompare(x, (any(j)).(string))
 	}
 	panic(fmt.Sprintf("immutable.defaultComparer: must set comparer for %T type", i))
 }
 
+// DefaultComparer returns a Comparer which sorts keys in their natural order
+// using the < and > operators.
+func DefaultComparer[K constraints.Ordered]() Comparer[K] {
+	return &orderedComparer[K]{}
+}
+
+// orderedComparer compares two values of an ordered type. Implements Comparer.
+type orderedComparer[K constraints.Ordered] struct{}
+
+// Compare returns -1 if a is less than b, returns 1 if a is greater than b, and
+// returns 0 if a is equal to b.
+func (c *orderedComparer[K]) Compare(a, b K) int {
+	return defaultCompare(a, b)
+}
+
+// CaseInsensitiveStringComparer implements Comparer for string keys that are
+// compared case-insensitively. Keys are ordered rune by rune using their
+// simple case folding and Compare returns 0 exactly when strings.EqualFold
+// reports the keys as equal.
+type CaseInsensitiveStringComparer struct{}
+
+// Compare returns -1 if a is less than b, returns 1 if a is greater than b,
+// and returns 0 if a is equal to b, ignoring case.
+func (c CaseInsensitiveStringComparer) Compare(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if cmp := defaultCompare(foldRune(ra), foldRune(rb)); cmp != 0 {
+			return cmp
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return defaultCompare(len(a), len(b))
+}
+
+// checkedComparer wraps a comparer and panics if a comparison is inconsistent.
+// Comparisons must return -1, 0, or 1 and must be antisymmetric. Implements
+// Comparer.
+type checkedComparer[K comparable] struct {
+	comparer Comparer[K]
+}
+
+// Compare returns the result of the wrapped comparer after checking it.
+func (c *checkedComparer[K]) Compare(a, b K) int {
+	x, y := c.comparer.Compare(a, b), c.comparer.Compare(b, a)
+	switch {
+	case x < -1 || x > 1:
+		panic(fmt.Sprintf("immutable.SortedMap: invalid comparer: Compare(%v, %v)=%d, expected -1, 0, or 1", a, b, x))
+	case x != -y:
+		panic(fmt.Sprintf("immutable.SortedMap: invalid comparer: Compare(%v, %v)=%d but Compare(%v, %v)=%d", a, b, x, b, a, y))
+	}
+	return x
+}
+
+// checkSortedMapPath panics if key is out of order with its neighbours on the
+// path from n to the leaf holding key. At each branch the key must fall between
+// the separator keys on either side of the child it was inserted into and at
+// the leaf it must fall between the adjacent entries.
+func checkSortedMapPath[K comparable, V any](n sortedMapNode[K, V], key K, c Comparer[K]) {
+	for {
+		switch node := n.(type) {
+		case *sortedMapBranchNode[K, V]:
+			idx := node.indexOf(key, c)
+
+			// Collect the separators around the child, substituting the key
+			// for the child's separator if they are equal.
+			keys := make([]K, 0, 5)
+			if idx > 0 {
+				keys = append(keys, node.elems[idx-1].key)
+			}
+			if c.Compare(node.elems[idx].key, key) != 0 {
+				keys = append(keys, node.elems[idx].key)
+			}
+			keys = append(keys, key)
+			for i := idx + 1; i < len(node.elems) && i <= idx+2; i++ {
+				keys = append(keys, node.elems[i].key)
+			}
+			checkSortedKeys(keys, c)
+			n = node.elems[idx].node
+
+		case *sortedMapLeafNode[K, V]:
+			idx := node.indexOf(key, c)
+			if idx == len(node.entries) || c.Compare(node.entries[idx].key, key) != 0 {
+				panic(fmt.Sprintf("immutable.SortedMap: invalid comparer: key %v not found after insert", key))
+			}
+
+			// Collect up to two entries on either side of the key.
+			lo, hi := idx-2, idx+3
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > len(node.entries) {
+				hi = len(node.entries)
+			}
+			keys := make([]K, 0, hi-lo)
+			for _, entry := range node.entries[lo:hi] {
+				keys = append(keys, entry.key)
+			}
+			checkSortedKeys(keys, c)
+			return
+		}
+	}
+}
+
+// checkSortedKeys panics unless each key is less than the next key and, by
+// transitivity, less than the key after that.
+func checkSortedKeys[K comparable](keys []K, c Comparer[K]) {
+	for i := 0; i+1 < len(keys); i++ {
+		if x := c.Compare(keys[i], keys[i+1]); x != -1 {
+			panic(fmt.Sprintf("immutable.SortedMap: invalid comparer: Compare(%v, %v)=%d, expected -1", keys[i], keys[i+1], x))
+		}
+	}
+	for i := 0; i+2 < len(keys); i++ {
+		if x := c.Compare(keys[i], keys[i+2]); x != -1 {
+			panic(fmt.Sprintf("immutable.SortedMap: invalid comparer: Compare(%v, %v)=-1 and Compare(%v, %v)=-1 but Compare(%v, %v)=%d",
+				keys[i], keys[i+1], keys[i+1], keys[i+2], keys[i], keys[i+2], x))
+		}
+	}
+}
+
 // defaultCompare only operates on constraints.Ordered.
 // For other types, users should bring their own comparers
+//
+// As with cmp.Compare, a NaN is ordered before any other value and is equal
+// to another NaN so that floating-point keys have a consistent order.
 func defaultCompare[K constraints.Ordered](i, j K) int {
 	if i < j {
 		return -1
 	} else if i > j {
 		return 1
 	}
+
+	// Only NaN is not equal to itself.
+	if iNaN, jNaN := i != i, j != j; iNaN && !jNaN {
+		return -1
+	} else if jNaN && !iNaN {
+		return 1
+	}
 	return 0
 }
 
@@ -2410,7 +4598,7 @@ func defaultCompare[K constraints.Ordered](i, j K) int {
 type reflectComparer[K comparable] struct{}
 
 // Compare returns -1 if a is less than b, returns 1 if a is greater than b, and
-// returns 0 if a is equal to b. Panic if a or b is not an int-ish or string-ish type.
+// returns 0 if a is equal to b. Panic if a or b is not an int-ish, float-ish, or string-ish type.
 func (c *reflectComparer[K]) Compare(a, b K) int {
 	switch reflect.TypeOf(a).Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -2427,12 +4615,49 @@ func (c *reflectComparer[K]) Compare(a, b K) int {
 			return 1
 		}
 		return 0
+	case reflect.Float32, reflect.Float64:
+		return defaultCompare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
 	case reflect.String:
 		return strings.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
 	}
 	panic(fmt.Sprintf("immutable.reflectComparer.Compare: must set comparer for %T type", a))
 }
 
+// maxStringElems is the maximum number of elements included by String methods.
+const maxStringElems = 100
+
+// elemWriter builds the string representation of a collection.
+type elemWriter struct {
+	buf strings.Builder
+	n   int
+}
+
+func newElemWriter(name string) *elemWriter {
+	w := &elemWriter{}
+	w.buf.WriteString(name)
+	w.buf.WriteByte('{')
+	return w
+}
+
+// write appends a formatted element. Returns false and appends "..." instead
+// if the element limit has been reached.
+func (w *elemWriter) write(format string, args ...any) bool {
+	if w.n > 0 {
+		w.buf.WriteString(", ")
+	}
+	if w.n == maxStringElems {
+		w.buf.WriteString("...")
+		return false
+	}
+	fmt.Fprintf(&w.buf, format, args...)
+	w.n++
+	return true
+}
+
+func (w *elemWriter) String() string {
+	return w.buf.String() + "}"
+}
+
 func assert(condition bool, message string) {
 	if !condition {
 		panic(message)
@@ -3,9 +3,14 @@ package immutable
 import (
 	"flag"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"golang.org/x/exp/constraints"
 )
@@ -115,6 +120,26 @@ func TestList(t *testing.T) {
 		}
 	})
 
+	t.Run("TrySet", func(t *testing.T) {
+		l := NewList("foo", "bar")
+		for _, index := range []int{-1, 2, 3} {
+			if other, ok := l.TrySet(index, "baz"); ok {
+				t.Fatalf("TrySet(%d) expected failure", index)
+			} else if other != l {
+				t.Fatalf("TrySet(%d) expected original list", index)
+			}
+		}
+
+		other, ok := l.TrySet(1, "baz")
+		if !ok {
+			t.Fatal("TrySet(1) expected success")
+		} else if v := other.Get(1); v != "baz" {
+			t.Fatalf("unexpected value: %v", v)
+		} else if v := l.Get(1); v != "bar" {
+			t.Fatalf("original list modified: %v", v)
+		}
+	})
+
 	t.Run("SliceStartOutOfRange", func(t *testing.T) {
 		var r string
 		func() {
@@ -167,16 +192,161 @@ func TestList(t *testing.T) {
 		}
 	})
 
+	t.Run("SliceShared", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		if other := l.Slice(0, l.Len()); other != l {
+			t.Fatal("expected full slice to return the original list")
+		}
+
+		other := l.Slice(2000, 3000)
+		if got, exp := other.Len(), 1000; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		}
+		for i := 0; i < other.Len(); i++ {
+			if got, exp := other.Get(i), i+2000; got != exp {
+				t.Fatalf("List.Get(%d)=%v, exp %v", i, got, exp)
+			}
+		}
+
+		// Leaves fully inside the slice are shared with the original list.
+		leafAt := func(l *List[int], index int) listNode[int] {
+			n, i := l.root, l.origin+index
+			for n.depth() > 0 {
+				n = n.(*listBranchNode[int]).children[(i>>(n.depth()*listNodeBits))&listNodeMask]
+			}
+			return n
+		}
+		if leafAt(l, 2500) != leafAt(other, 500) {
+			t.Fatal("expected slice to share leaf nodes with the original list")
+		}
+	})
+
+	t.Run("Prepend", func(t *testing.T) {
+		empty := NewList[int]()
+		l := empty.Prepend(1)
+		if got, exp := l.Len(), 1; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		} else if got, exp := l.Get(0), 1; got != exp {
+			t.Fatalf("List.Get(0)=%d, exp %d", got, exp)
+		} else if got, exp := empty.Len(), 0; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		}
+
+		// Prepend enough values onto a non-empty list to expand the root.
+		base := NewList(0, 1, 2)
+		l = base
+		for i := 1; i <= 2000; i++ {
+			l = l.Prepend(-i)
+			if got, exp := l.Get(0), -i; got != exp {
+				t.Fatalf("List.Get(0)=%d, exp %d", got, exp)
+			}
+		}
+		if got, exp := l.Len(), 2003; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		}
+		for i := 0; i < l.Len(); i++ {
+			if got, exp := l.Get(i), i-2000; got != exp {
+				t.Fatalf("List.Get(%d)=%d, exp %d", i, got, exp)
+			}
+		}
+		if got, exp := base.Len(), 3; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		} else if got, exp := base.Get(0), 0; got != exp {
+			t.Fatalf("List.Get(0)=%d, exp %d", got, exp)
+		}
+	})
+
+	t.Run("AppendMultiple", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 40; i++ {
+			l = l.Append(i)
+		}
+		if other := l.Append(); other != l {
+			t.Fatal("expected original list when appending no values")
+		}
+
+		// Appending to the same list twice must not overwrite shared nodes.
+		a := l.Append(100, 101, 102)
+		b := l.Append(200, 201)
+		if got, exp := l.Len(), 40; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		} else if got, exp := a.Len(), 43; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		} else if got, exp := b.Len(), 42; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		}
+		for i, exp := range []int{100, 101, 102} {
+			if got := a.Get(40 + i); got != exp {
+				t.Fatalf("List.Get(%d)=%v, exp %v", 40+i, got, exp)
+			}
+		}
+		for i, exp := range []int{200, 201} {
+			if got := b.Get(40 + i); got != exp {
+				t.Fatalf("List.Get(%d)=%v, exp %v", 40+i, got, exp)
+			}
+		}
+	})
+
+	t.Run("PrependMultiple", func(t *testing.T) {
+		l := NewList[int]().Prepend(1, 2)
+		if other := l.Prepend(); other != l {
+			t.Fatal("expected original list when prepending no values")
+		}
+
+		a := l.Prepend(10, 11)
+		b := l.Prepend(20)
+		if got, exp := fmt.Sprint(a.Get(0), a.Get(1), a.Get(2), a.Get(3)), "10 11 1 2"; got != exp {
+			t.Fatalf("unexpected list: %s, exp %s", got, exp)
+		} else if got, exp := fmt.Sprint(b.Get(0), b.Get(1), b.Get(2)), "20 1 2"; got != exp {
+			t.Fatalf("unexpected list: %s, exp %s", got, exp)
+		} else if got, exp := l.Len(), 2; got != exp {
+			t.Fatalf("List.Len()=%d, exp %d", got, exp)
+		}
+	})
+
 	t.Run("IteratorSeekOutOfBounds", func(t *testing.T) {
-		var r string
-		func() {
-			defer func() { r = recover().(string) }()
-			l := NewList[string]()
-			l = l.Append("foo")
-			l.Iterator().Seek(-1)
-		}()
-		if r != `immutable.ListIterator.Seek: index -1 out of bounds` {
-			t.Fatalf("unexpected panic: %q", r)
+		l := NewList("foo", "bar")
+		itr := l.Iterator()
+		itr.Next()
+		if itr.Seek(-1); itr.Done() {
+			t.Fatal("expected iterator not done")
+		} else if i, v := itr.Next(); i != 0 || v != "foo" {
+			t.Fatalf("Next()=<%d,%q>, exp <0,foo>", i, v)
+		}
+		for _, index := range []int{2, 100} {
+			if itr.Seek(index); !itr.Done() {
+				t.Fatalf("Seek(%d): expected iterator done", index)
+			} else if i, _ := itr.Next(); i != -1 {
+				t.Fatalf("Seek(%d): Next() index=%d, exp -1", index, i)
+			}
+		}
+
+		itr = NewList[string]().Iterator()
+		if itr.Seek(-1); !itr.Done() {
+			t.Fatal("expected empty iterator done")
+		}
+	})
+
+	t.Run("IteratorSeekMiddle", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		itr := l.Iterator()
+		for i := 0; i < 10; i++ {
+			itr.Next()
+		}
+		itr.Seek(500)
+		for exp := 500; exp < 1000; exp++ {
+			if i, v := itr.Next(); i != exp || v != exp {
+				t.Fatalf("Next()=<%d,%d>, exp <%d,%d>", i, v, exp, exp)
+			}
+		}
+		if !itr.Done() {
+			t.Fatal("expected iterator done")
 		}
 	})
 
@@ -245,356 +415,1254 @@ func TestList(t *testing.T) {
 	})
 }
 
-// TList represents a list that operates on a standard Go slice & immutable list.
-type TList struct {
-	im, prev *List[int]
-	builder  *ListBuilder[int]
-	std      []int
-}
+func TestList_Concat(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		l := NewList(1, 2, 3)
+		if other := l.Concat(NewList[int]()); other != l {
+			t.Fatal("expected receiver when concatenating an empty list")
+		} else if other := NewList[int]().Concat(l); other != l {
+			t.Fatal("expected other list when concatenating onto an empty list")
+		} else if other := l.Concat(nil); other != l {
+			t.Fatal("expected receiver when concatenating a nil list")
+		}
+	})
 
-// NewTList returns a new instance of TList.
-func NewTList() *TList {
-	return &TList{
-		im:      NewList[int](),
-		builder: NewListBuilder[int](),
-	}
-}
+	for _, tt := range []struct {
+		name string
+		n, m int
+	}{
+		{"Equal", 5000, 5000},
+		{"SmallerOther", 5000, 37},
+		{"LargerOther", 37, 5000},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := NewList[int](), NewList[int]()
+			for i := 0; i < tt.n; i++ {
+				a = a.Append(i)
+			}
+			for i := 0; i < tt.m; i++ {
+				b = b.Append(tt.n + i)
+			}
 
-// Len returns the size of the list.
-func (l *TList) Len() int {
-	return len(l.std)
-}
+			l := a.Concat(b)
+			if got, exp := l.Len(), tt.n+tt.m; got != exp {
+				t.Fatalf("List.Len()=%d, exp %d", got, exp)
+			}
+			for i := 0; i < l.Len(); i++ {
+				if got := l.Get(i); got != i {
+					t.Fatalf("List.Get(%d)=%d, exp %d", i, got, i)
+				}
+			}
 
-// ChooseIndex returns a randomly chosen, valid index from the standard slice.
-func (l *TList) ChooseIndex(rand *rand.Rand) int {
-	if len(l.std) == 0 {
-		return -1
+			// Neither input should be modified.
+			if got, exp := a.Len(), tt.n; got != exp {
+				t.Fatalf("List.Len()=%d, exp %d", got, exp)
+			} else if got, exp := b.Len(), tt.m; got != exp {
+				t.Fatalf("List.Len()=%d, exp %d", got, exp)
+			}
+			for i := 0; i < a.Len(); i++ {
+				if got := a.Get(i); got != i {
+					t.Fatalf("List.Get(%d)=%d, exp %d", i, got, i)
+				}
+			}
+			for i := 0; i < b.Len(); i++ {
+				if got, exp := b.Get(i), tt.n+i; got != exp {
+					t.Fatalf("List.Get(%d)=%d, exp %d", i, got, exp)
+				}
+			}
+		})
 	}
-	return rand.Intn(len(l.std))
 }
 
-// ChooseSliceIndices returns randomly chosen, valid indices for slicing.
-func (l *TList) ChooseSliceIndices(rand *rand.Rand) (start, end int) {
-	if len(l.std) == 0 {
-		return 0, 0
+func TestList_Insert(t *testing.T) {
+	const n = 1000
+	base := NewList[int]()
+	for i := 0; i < n; i++ {
+		base = base.Append(i)
 	}
-	start = rand.Intn(len(l.std))
-	end = rand.Intn(len(l.std)-start) + start
-	return start, end
-}
 
-// Append adds v to the end of slice and List.
-func (l *TList) Append(v int) {
-	l.prev = l.im
-	l.im = l.im.Append(v)
-	l.builder.Append(v)
-	l.std = append(l.std, v)
-}
+	for _, index := range []int{0, 1, 31, 32, 500, 998, 999, 1000} {
+		l := base.Insert(index, -1)
+		if got, exp := l.Len(), n+1; got != exp {
+			t.Fatalf("Insert(%d): Len()=%d, exp %d", index, got, exp)
+		}
+		for i := 0; i < l.Len(); i++ {
+			exp := i
+			if i == index {
+				exp = -1
+			} else if i > index {
+				exp = i - 1
+			}
+			if got := l.Get(i); got != exp {
+				t.Fatalf("Insert(%d): Get(%d)=%d, exp %d", index, i, got, exp)
+			}
+		}
+	}
 
-// Prepend adds v to the beginning of the slice and List.
-func (l *TList) Prepend(v int) {
-	l.prev = l.im
-	l.im = l.im.Prepend(v)
-	l.builder.Prepend(v)
-	l.std = append([]int{v}, l.std...)
-}
+	// Original list should be unchanged.
+	for i := 0; i < n; i++ {
+		if got := base.Get(i); got != i {
+			t.Fatalf("original Get(%d)=%d", i, got)
+		}
+	}
 
-// Set updates the value at index i to v in the slice and List.
-func (l *TList) Set(i, v int) {
-	l.prev = l.im
-	l.im = l.im.Set(i, v)
-	l.builder.Set(i, v)
-	l.std[i] = v
-}
+	if got := NewList[int]().Insert(0, 1).Get(0); got != 1 {
+		t.Fatalf("Get(0)=%d, exp 1", got)
+	}
 
-// Slice contracts the slice and List to the range of start/end indices.
-func (l *TList) Slice(start, end int) {
-	l.prev = l.im
-	l.im = l.im.Slice(start, end)
-	l.builder.Slice(start, end)
-	l.std = l.std[start:end]
+	t.Run("OutOfRange", func(t *testing.T) {
+		for _, index := range []int{-1, n + 1} {
+			var r string
+			func() {
+				defer func() { r = recover().(string) }()
+				base.Insert(index, 0)
+			}()
+			if exp := fmt.Sprintf("immutable.List.Insert: index %d out of bounds", index); r != exp {
+				t.Fatalf("unexpected panic: %q", r)
+			}
+		}
+	})
 }
 
-// Validate returns an error if the slice and List are different.
-func (l *TList) Validate() error {
-	if got, exp := l.im.Len(), len(l.std); got != exp {
-		return fmt.Errorf("Len()=%v, expected %d", got, exp)
-	} else if got, exp := l.builder.Len(), len(l.std); got != exp {
-		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+func TestList_Delete(t *testing.T) {
+	const n = 1000
+	base := NewList[int]()
+	for i := 0; i < n; i++ {
+		base = base.Append(i)
 	}
 
-	for i := range l.std {
-		if got, exp := l.im.Get(i), l.std[i]; got != exp {
-			return fmt.Errorf("Get(%d)=%v, expected %v", i, got, exp)
-		} else if got, exp := l.builder.Get(i), l.std[i]; got != exp {
-			return fmt.Errorf("Builder.List/Get(%d)=%v, expected %v", i, got, exp)
+	for _, index := range []int{0, 1, 31, 32, 500, 998, 999} {
+		l := base.Delete(index)
+		if got, exp := l.Len(), n-1; got != exp {
+			t.Fatalf("Delete(%d): Len()=%d, exp %d", index, got, exp)
+		}
+		for i := 0; i < l.Len(); i++ {
+			exp := i
+			if i >= index {
+				exp = i + 1
+			}
+			if got := l.Get(i); got != exp {
+				t.Fatalf("Delete(%d): Get(%d)=%d, exp %d", index, i, got, exp)
+			}
 		}
 	}
 
-	if err := l.validateForwardIterator("basic", l.im.Iterator()); err != nil {
-		return err
-	} else if err := l.validateBackwardIterator("basic", l.im.Iterator()); err != nil {
-		return err
+	if got := NewList(1).Delete(0).Len(); got != 0 {
+		t.Fatalf("Len()=%d, exp 0", got)
 	}
 
-	if err := l.validateForwardIterator("builder", l.builder.Iterator()); err != nil {
-		return err
-	} else if err := l.validateBackwardIterator("builder", l.builder.Iterator()); err != nil {
-		return err
-	}
-	return nil
+	t.Run("OutOfRange", func(t *testing.T) {
+		for _, index := range []int{-1, n} {
+			var r string
+			func() {
+				defer func() { r = recover().(string) }()
+				base.Delete(index)
+			}()
+			if exp := fmt.Sprintf("immutable.List.Delete: index %d out of bounds", index); r != exp {
+				t.Fatalf("unexpected panic: %q", r)
+			}
+		}
+	})
 }
 
-func (l *TList) validateForwardIterator(typ string, itr *ListIterator[int]) error {
-	for i := range l.std {
-		if j, v := itr.Next(); i != j || l.std[i] != v {
-			return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+func TestList_Reverse(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 31, 32, 33, 1000, 5000} {
+		l := NewList[int]()
+		for i := 0; i < n; i++ {
+			l = l.Append(i)
 		}
 
-		done := i == len(l.std)-1
-		if v := itr.Done(); v != done {
-			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		other := l.Reverse()
+		if n < 2 && other != l {
+			t.Fatalf("n=%d: expected original list", n)
+		} else if got := other.Len(); got != n {
+			t.Fatalf("n=%d: Len()=%d", n, got)
+		}
+		for i := 0; i < n; i++ {
+			if got, exp := other.Get(i), l.Get(n-1-i); got != exp {
+				t.Fatalf("n=%d: Get(%d)=%d, exp %d", n, i, got, exp)
+			}
 		}
 	}
-	if i, v := itr.Next(); i != -1 || v != 0 {
-		return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected DONE [%s]", i, v, typ)
-	}
-	return nil
 }
 
-func (l *TList) validateBackwardIterator(typ string, itr *ListIterator[int]) error {
-	itr.Last()
-	for i := len(l.std) - 1; i >= 0; i-- {
-		if j, v := itr.Prev(); i != j || l.std[i] != v {
-			return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+func TestList_Find(t *testing.T) {
+	l := NewList(3, 8, 5, 10, 7)
+	isEven := func(v int) bool { return v%2 == 0 }
+
+	t.Run("Start", func(t *testing.T) {
+		if v, i, ok := l.Find(func(v int) bool { return v < 5 }); !ok || v != 3 || i != 0 {
+			t.Fatalf("Find()=<%v,%v,%v>", v, i, ok)
 		}
+	})
 
-		done := i == 0
-		if v := itr.Done(); v != done {
-			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+	t.Run("First", func(t *testing.T) {
+		// Only elements up to the first match should be tested.
+		var calls int
+		pred := func(v int) bool { calls++; return isEven(v) }
+		if v, i, ok := l.Find(pred); !ok || v != 8 || i != 1 {
+			t.Fatalf("Find()=<%v,%v,%v>", v, i, ok)
+		} else if calls != 2 {
+			t.Fatalf("unexpected pred calls: %d", calls)
+		}
+	})
+
+	t.Run("End", func(t *testing.T) {
+		if v, i, ok := l.Find(func(v int) bool { return v == 7 }); !ok || v != 7 || i != 4 {
+			t.Fatalf("Find()=<%v,%v,%v>", v, i, ok)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if v, i, ok := l.Find(func(v int) bool { return v > 10 }); ok || v != 0 || i != -1 {
+			t.Fatalf("Find()=<%v,%v,%v>", v, i, ok)
+		} else if v, i, ok := NewList[int]().Find(isEven); ok || v != 0 || i != -1 {
+			t.Fatalf("Find() on empty list=<%v,%v,%v>", v, i, ok)
+		}
+	})
+}
+
+func TestList_IndexOf(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+
+	t.Run("Empty", func(t *testing.T) {
+		l := NewList[int]()
+		if got := l.IndexOf(1, eq); got != -1 {
+			t.Fatalf("IndexOf()=%d, expected -1", got)
+		} else if l.Contains(1, eq) {
+			t.Fatal("expected Contains() to be false")
+		}
+	})
+
+	t.Run("Found", func(t *testing.T) {
+		l := NewList(5, 6, 7, 6)
+
+		// Only elements up to the first match should be compared.
+		var calls int
+		counting := func(a, b int) bool { calls++; return a == b }
+		if got := l.IndexOf(6, counting); got != 1 {
+			t.Fatalf("IndexOf()=%d, expected 1", got)
+		} else if calls != 2 {
+			t.Fatalf("unexpected eq calls: %d", calls)
+		} else if !l.Contains(7, eq) {
+			t.Fatal("expected Contains() to be true")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		l := NewList(5, 6, 7)
+		if got := l.IndexOf(8, eq); got != -1 {
+			t.Fatalf("IndexOf()=%d, expected -1", got)
+		} else if l.Contains(8, eq) {
+			t.Fatal("expected Contains() to be false")
 		}
+	})
+}
+
+func TestList_Equal(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+
+	a := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		a = a.Append(i)
 	}
-	if i, v := itr.Prev(); i != -1 || v != 0 {
-		return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected DONE [%s]", i, v, typ)
+
+	// Build the same contents by prepending instead.
+	b := NewList[int]()
+	for i := 999; i >= 0; i-- {
+		b = b.Prepend(i)
 	}
-	return nil
-}
 
-func BenchmarkList_Append(b *testing.B) {
-	b.ReportAllocs()
-	l := NewList[int]()
-	for i := 0; i < b.N; i++ {
-		l = l.Append(i)
+	if !a.Equal(a, eq) {
+		t.Fatal("expected list to equal itself")
+	} else if !a.Equal(b, eq) || !ListEqual(a, b) {
+		t.Fatal("expected lists to be equal")
+	} else if a.Equal(b.Set(500, -1), eq) || ListEqual(a, b.Set(500, -1)) {
+		t.Fatal("expected lists with different elements to be unequal")
+	} else if a.Equal(b.Slice(0, 999), eq) {
+		t.Fatal("expected lists with different lengths to be unequal")
+	} else if !NewList[int]().Equal(NewList[int](), eq) {
+		t.Fatal("expected empty lists to be equal")
 	}
 }
 
-func BenchmarkList_Prepend(b *testing.B) {
-	b.ReportAllocs()
-	l := NewList[int]()
-	for i := 0; i < b.N; i++ {
-		l = l.Prepend(i)
+func TestList_Clear(t *testing.T) {
+	l := NewList(1, 2, 3)
+	other := l.Clear()
+	if got := other.Len(); got != 0 {
+		t.Fatalf("Len()=%d, exp 0", got)
+	} else if other.Clear() != other {
+		t.Fatal("expected empty list to be returned")
+	} else if got := l.Len(); got != 3 {
+		t.Fatalf("original Len()=%d, exp 3", got)
+	} else if got := other.Append(4).Get(0); got != 4 {
+		t.Fatalf("Get(0)=%d, exp 4", got)
 	}
 }
 
-func BenchmarkList_Set(b *testing.B) {
-	const n = 10000
-
+func TestList_GetAt(t *testing.T) {
 	l := NewList[int]()
-	for i := 0; i < 10000; i++ {
+	for i := 0; i < 1000; i++ {
 		l = l.Append(i)
 	}
-	b.ReportAllocs()
-	b.ResetTimer()
+	if got := l.Len64(); got != 1000 {
+		t.Fatalf("Len64()=%d, exp 1000", got)
+	}
+	for i := int64(0); i < 1000; i++ {
+		if got := l.GetAt(i); got != int(i) {
+			t.Fatalf("GetAt(%d)=%d", i, got)
+		}
+	}
 
-	for i := 0; i < b.N; i++ {
-		l = l.Set(i%n, i*10)
+	// Indices which truncate to a valid 32-bit index must still be rejected.
+	for _, index := range []int64{-1, 1000, 1<<32 + 1, math.MaxInt64, math.MinInt64} {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			l.GetAt(index)
+		}()
+		if exp := fmt.Sprintf("immutable.List.GetAt: index %d out of bounds", index); r != exp {
+			t.Fatalf("unexpected panic: %q", r)
+		}
 	}
 }
 
-func BenchmarkList_Iterator(b *testing.B) {
-	const n = 10000
+func TestList_HeadTail(t *testing.T) {
 	l := NewList[int]()
-	for i := 0; i < 10000; i++ {
+	for i := 0; i < 100; i++ {
 		l = l.Append(i)
 	}
-	b.ReportAllocs()
-	b.ResetTimer()
+	if v, ok := l.Last(); !ok || v != 99 {
+		t.Fatalf("Last()=<%v,%v>", v, ok)
+	}
 
-	b.Run("Forward", func(b *testing.B) {
-		itr := l.Iterator()
-		for i := 0; i < b.N; i++ {
-			if i%n == 0 {
-				itr.First()
+	for exp, other := 0, l; ; exp++ {
+		v, ok := other.Head()
+		if !ok {
+			if exp != 100 {
+				t.Fatalf("Head() failed after %d elements", exp)
 			}
-			itr.Next()
+			break
+		} else if v != exp {
+			t.Fatalf("Head()=%d, exp %d", v, exp)
+		} else if last, _ := other.Last(); last != 99 {
+			t.Fatalf("Last()=%d, exp 99", last)
 		}
-	})
+		other = other.Tail()
+		if got := other.Len(); got != 99-exp {
+			t.Fatalf("Tail().Len()=%d, exp %d", got, 99-exp)
+		}
+	}
 
-	b.Run("Reverse", func(b *testing.B) {
-		itr := l.Iterator()
-		for i := 0; i < b.N; i++ {
-			if i%n == 0 {
-				itr.Last()
+	empty := NewList[int]()
+	if _, ok := empty.Head(); ok {
+		t.Fatal("expected Head() on empty list to fail")
+	} else if _, ok := empty.Last(); ok {
+		t.Fatal("expected Last() on empty list to fail")
+	} else if other := empty.Tail(); other.Len() != 0 {
+		t.Fatalf("Tail().Len()=%d, exp 0", other.Len())
+	} else if l.Len() != 100 {
+		t.Fatalf("original Len()=%d, exp 100", l.Len())
+	}
+}
+
+func TestList_Truncate(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
+
+	for _, n := range []int{0, 1, 31, 32, 33, 500, 999} {
+		other := l.Truncate(n)
+		if got := other.Len(); got != n {
+			t.Fatalf("Truncate(%d): Len()=%d", n, got)
+		}
+		for i := 0; i < n; i++ {
+			if got := other.Get(i); got != i {
+				t.Fatalf("Truncate(%d): Get(%d)=%d", n, i, got)
 			}
-			itr.Prev()
 		}
-	})
-}
+		if got := other.Append(-1).Get(n); got != -1 {
+			t.Fatalf("Truncate(%d): Append()=%d", n, got)
+		}
+	}
 
-func BenchmarkBuiltinSlice_Append(b *testing.B) {
-	b.Run("Int", func(b *testing.B) {
-		b.ReportAllocs()
-		var a []int
-		for i := 0; i < b.N; i++ {
-			a = append(a, i)
+	if l.Truncate(1000) != l || l.Truncate(2000) != l {
+		t.Fatal("expected original list when length is at least Len()")
+	} else if got := l.Len(); got != 1000 {
+		t.Fatalf("original Len()=%d, exp 1000", got)
+	}
+	for i := 0; i < 1000; i++ {
+		if got := l.Get(i); got != i {
+			t.Fatalf("original Get(%d)=%d", i, got)
 		}
-	})
-	b.Run("Interface", func(b *testing.B) {
-		b.ReportAllocs()
-		var a []interface{}
-		for i := 0; i < b.N; i++ {
-			a = append(a, i)
+	}
+
+	t.Run("Negative", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			l.Truncate(-1)
+		}()
+		if r != `immutable.List.Truncate: length -1 out of bounds` {
+			t.Fatalf("unexpected panic: %q", r)
 		}
 	})
 }
 
-func BenchmarkListBuilder_Append(b *testing.B) {
-	b.ReportAllocs()
-	builder := NewListBuilder[int]()
-	for i := 0; i < b.N; i++ {
-		builder.Append(i)
+func TestNewListRepeat(t *testing.T) {
+	for _, n := range []int{0, 1, 10000} {
+		l := NewListRepeat("x", n)
+		if got := l.Len(); got != n {
+			t.Fatalf("NewListRepeat(%d): Len()=%d", n, got)
+		}
+		for i := 0; i < n; i++ {
+			if got := l.Get(i); got != "x" {
+				t.Fatalf("NewListRepeat(%d): Get(%d)=%q", n, i, got)
+			}
+		}
 	}
-}
 
-func BenchmarkListBuilder_Prepend(b *testing.B) {
-	b.ReportAllocs()
-	builder := NewListBuilder[int]()
-	for i := 0; i < b.N; i++ {
-		builder.Prepend(i)
-	}
+	t.Run("Negative", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			NewListRepeat(0, -1)
+		}()
+		if r != `immutable.NewListRepeat: negative count -1` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
 }
 
-func BenchmarkListBuilder_Set(b *testing.B) {
-	const n = 10000
+func TestList_Fill(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
 
-	builder := NewListBuilder[int]()
-	for i := 0; i < 10000; i++ {
-		builder.Append(i)
+	for _, tt := range [][2]int{{0, 1}, {10, 50}, {999, 1000}, {100, 900}, {0, 1000}} {
+		start, stop := tt[0], tt[1]
+		other := l.Fill(start, stop, -1)
+		if other.Len() != 1000 {
+			t.Fatalf("Fill(%d, %d): Len()=%d", start, stop, other.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			exp := i
+			if i >= start && i < stop {
+				exp = -1
+			}
+			if got := other.Get(i); got != exp {
+				t.Fatalf("Fill(%d, %d): Get(%d)=%d, exp %d", start, stop, i, got, exp)
+			} else if got := l.Get(i); got != i {
+				t.Fatalf("Fill(%d, %d): original Get(%d)=%d", start, stop, i, got)
+			}
+		}
 	}
-	b.ReportAllocs()
-	b.ResetTimer()
 
-	for i := 0; i < b.N; i++ {
-		builder.Set(i%n, i*10)
+	if l.Fill(5, 5, -1) != l {
+		t.Fatal("expected original list for empty range")
 	}
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		for _, tt := range []struct {
+			start, stop int
+			exp         string
+		}{
+			{-1, 5, `immutable.List.Fill: start index -1 out of bounds`},
+			{0, 1001, `immutable.List.Fill: stop index 1001 out of bounds`},
+			{6, 5, `immutable.List.Fill: invalid range: [6:5]`},
+		} {
+			var r string
+			func() {
+				defer func() { r = recover().(string) }()
+				l.Fill(tt.start, tt.stop, 0)
+			}()
+			if r != tt.exp {
+				t.Fatalf("unexpected panic: %q", r)
+			}
+		}
+	})
 }
 
-func ExampleList_Append() {
-	l := NewList[string]()
-	l = l.Append("foo")
-	l = l.Append("bar")
-	l = l.Append("baz")
+func TestList_Swap(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 100; i++ {
+		l = l.Append(i)
+	}
 
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	fmt.Println(l.Get(2))
-	// Output:
-	// foo
-	// bar
-	// baz
-}
+	for _, tt := range [][2]int{{0, 99}, {99, 0}, {10, 11}, {31, 32}} {
+		i, j := tt[0], tt[1]
+		other := l.Swap(i, j)
+		for k := 0; k < 100; k++ {
+			exp := k
+			if k == i {
+				exp = j
+			} else if k == j {
+				exp = i
+			}
+			if got := other.Get(k); got != exp {
+				t.Fatalf("Swap(%d, %d): Get(%d)=%d, exp %d", i, j, k, got, exp)
+			} else if got := l.Get(k); got != k {
+				t.Fatalf("Swap(%d, %d): original Get(%d)=%d", i, j, k, got)
+			}
+		}
+	}
 
-func ExampleList_Prepend() {
-	l := NewList[string]()
-	l = l.Prepend("foo")
-	l = l.Prepend("bar")
-	l = l.Prepend("baz")
+	if l.Swap(5, 5) != l {
+		t.Fatal("expected original list when indices are equal")
+	}
 
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	fmt.Println(l.Get(2))
-	// Output:
-	// baz
-	// bar
-	// foo
+	t.Run("OutOfRange", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			l.Swap(0, 100)
+		}()
+		if r != `immutable.List.Swap: index 100 out of bounds` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
 }
 
-func ExampleList_Set() {
-	l := NewList[string]()
-	l = l.Append("foo")
-	l = l.Append("bar")
-	l = l.Set(1, "baz")
+func TestList_Filter(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
 
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	// Output:
-	// foo
-	// baz
-}
+	t.Run("All", func(t *testing.T) {
+		if other := l.Filter(func(v int) bool { return true }); other != l {
+			t.Fatal("expected original list when all elements are retained")
+		}
+	})
 
-func ExampleList_Slice() {
-	l := NewList[string]()
-	l = l.Append("foo")
-	l = l.Append("bar")
-	l = l.Append("baz")
-	l = l.Slice(1, 3)
+	t.Run("None", func(t *testing.T) {
+		if other := l.Filter(func(v int) bool { return false }); other.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", other.Len())
+		}
+	})
 
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	// Output:
-	// bar
-	// baz
+	t.Run("Alternating", func(t *testing.T) {
+		other := l.Filter(func(v int) bool { return v%2 == 0 })
+		if other.Len() != 500 {
+			t.Fatalf("Len()=%d, expected 500", other.Len())
+		}
+		for i := 0; i < other.Len(); i++ {
+			if v := other.Get(i); v != i*2 {
+				t.Fatalf("Get(%d)=%d, expected %d", i, v, i*2)
+			}
+		}
+		if l.Len() != 1000 {
+			t.Fatalf("unexpected mutation of original list: Len()=%d", l.Len())
+		}
+	})
 }
 
-func ExampleList_Iterator() {
-	l := NewList[string]()
-	l = l.Append("foo")
-	l = l.Append("bar")
-	l = l.Append("baz")
+func TestList_ForEach(t *testing.T) {
+	l := NewList(1, 2, 3, 4, 5)
 
-	itr := l.Iterator()
-	for !itr.Done() {
-		i, v := itr.Next()
-		fmt.Println(i, v)
+	var sum, n int
+	l.ForEach(func(i, v int) bool {
+		if i != n {
+			t.Fatalf("index=%d, expected %d", i, n)
+		}
+		sum += v
+		n++
+		return true
+	})
+	if sum != 15 {
+		t.Fatalf("sum=%d, expected 15", sum)
+	}
+
+	n = 0
+	l.ForEach(func(i, v int) bool {
+		n++
+		return v < 3
+	})
+	if n != 3 {
+		t.Fatalf("visited %d elements, expected 3", n)
 	}
-	// Output:
-	// 0 foo
-	// 1 bar
-	// 2 baz
 }
 
-func ExampleList_Iterator_reverse() {
-	l := NewList[string]()
-	l = l.Append("foo")
-	l = l.Append("bar")
-	l = l.Append("baz")
+func TestListChunk(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 100; i++ {
+		l = l.Append(i)
+	}
 
-	itr := l.Iterator()
-	itr.Last()
-	for !itr.Done() {
-		i, v := itr.Prev()
-		fmt.Println(i, v)
+	for _, tt := range []struct {
+		name string
+		size int
+		lens []int
+	}{
+		{"Exact", 25, []int{25, 25, 25, 25}},
+		{"Remainder", 30, []int{30, 30, 30, 10}},
+		{"One", 1, nil},
+		{"Len", 100, []int{100}},
+		{"LargerThanLen", 1000, []int{100}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := ListChunk(l, tt.size)
+			if tt.lens == nil {
+				tt.lens = make([]int, l.Len())
+				for i := range tt.lens {
+					tt.lens[i] = 1
+				}
+			}
+			if chunks.Len() != len(tt.lens) {
+				t.Fatalf("Len()=%d, exp %d", chunks.Len(), len(tt.lens))
+			}
+			var next int
+			for i, exp := range tt.lens {
+				chunk := chunks.Get(i)
+				if chunk.Len() != exp {
+					t.Fatalf("chunk %d: Len()=%d, exp %d", i, chunk.Len(), exp)
+				}
+				for j := 0; j < chunk.Len(); j++ {
+					if got := chunk.Get(j); got != next {
+						t.Fatalf("chunk %d: Get(%d)=%d, exp %d", i, j, got, next)
+					}
+					next++
+				}
+			}
+		})
 	}
-	// Output:
-	// 2 baz
-	// 1 bar
-	// 0 foo
-}
 
-func ExampleListBuilder_Append() {
-	b := NewListBuilder[string]()
-	b.Append("foo")
-	b.Append("bar")
-	b.Append("baz")
+	t.Run("Empty", func(t *testing.T) {
+		if got := ListChunk(NewList[int](), 10).Len(); got != 0 {
+			t.Fatalf("Len()=%d, exp 0", got)
+		}
+	})
 
-	l := b.List()
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	fmt.Println(l.Get(2))
-	// Output:
-	// foo
-	// bar
-	// baz
+	t.Run("InvalidSize", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			ListChunk(l, 0)
+		}()
+		if r != `immutable.ListChunk: invalid chunk size 0` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+}
+
+func TestListSort(t *testing.T) {
+	type item struct{ key, seq int }
+	l := NewList[item]()
+	rand := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		l = l.Append(item{key: rand.Intn(50), seq: i})
+	}
+	less := func(a, b item) bool { return a.key < b.key }
+
+	for name, fn := range map[string]func(*List[item], func(a, b item) bool) *List[item]{
+		"Unstable": ListSort[item],
+		"Stable":   ListSortStable[item],
+	} {
+		t.Run(name, func(t *testing.T) {
+			sorted := fn(l, less)
+			if sorted.Len() != l.Len() {
+				t.Fatalf("Len()=%d, exp %d", sorted.Len(), l.Len())
+			}
+			for i := 1; i < sorted.Len(); i++ {
+				prev, cur := sorted.Get(i-1), sorted.Get(i)
+				if cur.key < prev.key {
+					t.Fatalf("unsorted at %d: %v before %v", i, prev, cur)
+				} else if name == "Stable" && cur.key == prev.key && cur.seq < prev.seq {
+					t.Fatalf("unstable at %d: %v before %v", i, prev, cur)
+				}
+			}
+			for i := 0; i < l.Len(); i++ {
+				if got := l.Get(i); got.seq != i {
+					t.Fatalf("original Get(%d)=%v", i, got)
+				}
+			}
+		})
+	}
+
+	if got := ListSort(NewList[int](), func(a, b int) bool { return a < b }); got.Len() != 0 {
+		t.Fatalf("Len()=%d, exp 0", got.Len())
+	}
+}
+
+func TestListToMap(t *testing.T) {
+	type item struct {
+		id       int
+		category string
+	}
+	l := NewList[item]()
+	for i := 0; i < 100; i++ {
+		l = l.Append(item{id: i, category: []string{"a", "b", "c"}[i%3]})
+	}
+
+	t.Run("Index", func(t *testing.T) {
+		m := ListToMap(l, nil, func(v item) int { return v.id })
+		if m.Len() != 100 {
+			t.Fatalf("Len()=%d, exp 100", m.Len())
+		}
+		for i := 0; i < 100; i++ {
+			if v, ok := m.Get(i); !ok || v.id != i {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+
+		// Later elements win when keys collide.
+		byCategory := ListToMap(l, nil, func(v item) string { return v.category })
+		if v, _ := byCategory.Get("a"); v.id != 99 {
+			t.Fatalf("Get(a).id=%d, exp 99", v.id)
+		} else if v, _ := byCategory.Get("c"); v.id != 98 {
+			t.Fatalf("Get(c).id=%d, exp 98", v.id)
+		}
+	})
+
+	t.Run("GroupBy", func(t *testing.T) {
+		m := ListGroupBy(l, nil, func(v item) string { return v.category })
+		if m.Len() != 3 {
+			t.Fatalf("Len()=%d, exp 3", m.Len())
+		}
+		for i, category := range []string{"a", "b", "c"} {
+			group, ok := m.Get(category)
+			if !ok {
+				t.Fatalf("Get(%q) missing", category)
+			}
+			exp := i
+			itr := group.Iterator()
+			for !itr.Done() {
+				_, v := itr.Next()
+				if v.id != exp || v.category != category {
+					t.Fatalf("group %q: unexpected item %v, exp id %d", category, v, exp)
+				}
+				exp += 3
+			}
+			if exp < 100 {
+				t.Fatalf("group %q: missing items from id %d", category, exp)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if m := ListGroupBy(NewList[item](), nil, func(v item) int { return v.id }); m.Len() != 0 {
+			t.Fatalf("Len()=%d, exp 0", m.Len())
+		}
+	})
+}
+
+func TestListMap(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		if l := ListMap(NewList[int](), strconv.Itoa); l.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", l.Len())
+		}
+		if l := ListMap(nil, strconv.Itoa); l.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0 for nil list", l.Len())
+		}
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		src := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			src = src.Append(i)
+		}
+
+		l := ListMap(src, strconv.Itoa)
+		if l.Len() != src.Len() {
+			t.Fatalf("Len()=%d, expected %d", l.Len(), src.Len())
+		}
+		for i := 0; i < l.Len(); i++ {
+			if v := l.Get(i); v != strconv.Itoa(i) {
+				t.Fatalf("Get(%d)=%q, expected %q", i, v, strconv.Itoa(i))
+			}
+		}
+	})
+}
+
+func TestListReduce(t *testing.T) {
+	l := NewList(1, 2, 3, 4)
+
+	if sum := ListReduce(l, 0, func(acc, v int) int { return acc + v }); sum != 10 {
+		t.Fatalf("sum=%d, expected 10", sum)
+	}
+	if s := ListReduce(l, "", func(acc string, v int) string { return acc + strconv.Itoa(v) }); s != "1234" {
+		t.Fatalf("result=%q, expected %q", s, "1234")
+	}
+	if s := ListReduce(NewList[int](), "x", func(acc string, v int) string { return acc + strconv.Itoa(v) }); s != "x" {
+		t.Fatalf("result=%q, expected initial value", s)
+	}
+}
+
+// TList represents a list that operates on a standard Go slice & immutable list.
+type TList struct {
+	im, prev *List[int]
+	builder  *ListBuilder[int]
+	std      []int
+}
+
+// NewTList returns a new instance of TList.
+func NewTList() *TList {
+	return &TList{
+		im:      NewList[int](),
+		builder: NewListBuilder[int](),
+	}
+}
+
+// Len returns the size of the list.
+func (l *TList) Len() int {
+	return len(l.std)
+}
+
+// ChooseIndex returns a randomly chosen, valid index from the standard slice.
+func (l *TList) ChooseIndex(rand *rand.Rand) int {
+	if len(l.std) == 0 {
+		return -1
+	}
+	return rand.Intn(len(l.std))
+}
+
+// ChooseSliceIndices returns randomly chosen, valid indices for slicing.
+func (l *TList) ChooseSliceIndices(rand *rand.Rand) (start, end int) {
+	if len(l.std) == 0 {
+		return 0, 0
+	}
+	start = rand.Intn(len(l.std))
+	end = rand.Intn(len(l.std)-start) + start
+	return start, end
+}
+
+// Append adds v to the end of slice and List.
+func (l *TList) Append(v int) {
+	l.prev = l.im
+	l.im = l.im.Append(v)
+	l.builder.Append(v)
+	l.std = append(l.std, v)
+}
+
+// Prepend adds v to the beginning of the slice and List.
+func (l *TList) Prepend(v int) {
+	l.prev = l.im
+	l.im = l.im.Prepend(v)
+	l.builder.Prepend(v)
+	l.std = append([]int{v}, l.std...)
+}
+
+// Set updates the value at index i to v in the slice and List.
+func (l *TList) Set(i, v int) {
+	l.prev = l.im
+	l.im = l.im.Set(i, v)
+	l.builder.Set(i, v)
+	l.std[i] = v
+}
+
+// Slice contracts the slice and List to the range of start/end indices.
+func (l *TList) Slice(start, end int) {
+	l.prev = l.im
+	l.im = l.im.Slice(start, end)
+	l.builder.Slice(start, end)
+	l.std = l.std[start:end]
+}
+
+// Validate returns an error if the slice and List are different.
+func (l *TList) Validate() error {
+	if got, exp := l.im.Len(), len(l.std); got != exp {
+		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+	} else if got, exp := l.builder.Len(), len(l.std); got != exp {
+		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+	}
+
+	for i := range l.std {
+		if got, exp := l.im.Get(i), l.std[i]; got != exp {
+			return fmt.Errorf("Get(%d)=%v, expected %v", i, got, exp)
+		} else if got, exp := l.builder.Get(i), l.std[i]; got != exp {
+			return fmt.Errorf("Builder.List/Get(%d)=%v, expected %v", i, got, exp)
+		}
+	}
+
+	if err := l.validateForwardIterator("basic", l.im.Iterator()); err != nil {
+		return err
+	} else if err := l.validateBackwardIterator("basic", l.im.Iterator()); err != nil {
+		return err
+	}
+
+	if err := l.validateForwardIterator("builder", l.builder.Iterator()); err != nil {
+		return err
+	} else if err := l.validateBackwardIterator("builder", l.builder.Iterator()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *TList) validateForwardIterator(typ string, itr *ListIterator[int]) error {
+	for i := range l.std {
+		if j, v := itr.Next(); i != j || l.std[i] != v {
+			return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+		}
+
+		done := i == len(l.std)-1
+		if v := itr.Done(); v != done {
+			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		}
+	}
+	if i, v := itr.Next(); i != -1 || v != 0 {
+		return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected DONE [%s]", i, v, typ)
+	}
+	return nil
+}
+
+func (l *TList) validateBackwardIterator(typ string, itr *ListIterator[int]) error {
+	itr.Last()
+	for i := len(l.std) - 1; i >= 0; i-- {
+		if j, v := itr.Prev(); i != j || l.std[i] != v {
+			return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+		}
+
+		done := i == 0
+		if v := itr.Done(); v != done {
+			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		}
+	}
+	if i, v := itr.Prev(); i != -1 || v != 0 {
+		return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected DONE [%s]", i, v, typ)
+	}
+	return nil
+}
+
+func BenchmarkList_Append(b *testing.B) {
+	b.ReportAllocs()
+	l := NewList[int]()
+	for i := 0; i < b.N; i++ {
+		l = l.Append(i)
+	}
+}
+
+func BenchmarkList_AppendMultiple(b *testing.B) {
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+
+	b.Run("Variadic", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewList[int]().Append(values...)
+		}
+	})
+
+	b.Run("Individual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l := NewList[int]()
+			for _, v := range values {
+				l = l.Append(v)
+			}
+		}
+	})
+}
+
+func BenchmarkList_Prepend(b *testing.B) {
+	b.ReportAllocs()
+	l := NewList[int]()
+	for i := 0; i < b.N; i++ {
+		l = l.Prepend(i)
+	}
+}
+
+func BenchmarkList_Set(b *testing.B) {
+	const n = 10000
+
+	l := NewList[int]()
+	for i := 0; i < 10000; i++ {
+		l = l.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l = l.Set(i%n, i*10)
+	}
+}
+
+func BenchmarkList_Slice(b *testing.B) {
+	const n = 100000
+	l := NewList[int]()
+	for i := 0; i < n; i++ {
+		l = l.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.Run("Slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Slice(n/4, n-n/4)
+		}
+	})
+
+	b.Run("Builder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewListBuilder[int]()
+			for j := n / 4; j < n-n/4; j++ {
+				builder.Append(l.Get(j))
+			}
+			builder.List()
+		}
+	})
+}
+
+func BenchmarkList_Iterator(b *testing.B) {
+	const n = 10000
+	l := NewList[int]()
+	for i := 0; i < 10000; i++ {
+		l = l.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.Run("Forward", func(b *testing.B) {
+		itr := l.Iterator()
+		for i := 0; i < b.N; i++ {
+			if i%n == 0 {
+				itr.First()
+			}
+			itr.Next()
+		}
+	})
+
+	b.Run("Reverse", func(b *testing.B) {
+		itr := l.Iterator()
+		for i := 0; i < b.N; i++ {
+			if i%n == 0 {
+				itr.Last()
+			}
+			itr.Prev()
+		}
+	})
+}
+
+func BenchmarkBuiltinSlice_Append(b *testing.B) {
+	b.Run("Int", func(b *testing.B) {
+		b.ReportAllocs()
+		var a []int
+		for i := 0; i < b.N; i++ {
+			a = append(a, i)
+		}
+	})
+	b.Run("Interface", func(b *testing.B) {
+		b.ReportAllocs()
+		var a []interface{}
+		for i := 0; i < b.N; i++ {
+			a = append(a, i)
+		}
+	})
+}
+
+func TestNewListBuilderSized(t *testing.T) {
+	const hint = 5000
+	for _, n := range []int{0, 1, 10, 1000, hint, 2 * hint} {
+		b := NewListBuilderSized[int](hint)
+		for i := 0; i < n; i++ {
+			b.Append(i)
+		}
+		l := b.List()
+
+		if l.Len() != n {
+			t.Fatalf("Len()=%d, expected %d", l.Len(), n)
+		}
+		for i := 0; i < n; i++ {
+			if v := l.Get(i); v != i {
+				t.Fatalf("Get(%d)=%d", i, v)
+			}
+		}
+
+		// Unused reserved nodes are released so iteration and further edits
+		// behave as for an unsized builder.
+		var count int
+		for itr := l.Iterator(); !itr.Done(); itr.Next() {
+			count++
+		}
+		if count != n {
+			t.Fatalf("iterated %d elements, expected %d", count, n)
+		} else if other := l.Append(-1).Prepend(-2); other.Len() != n+2 || other.Get(0) != -2 || other.Get(n+1) != -1 {
+			t.Fatalf("unexpected list after Append() and Prepend(): Len()=%d", other.Len())
+		}
+	}
+
+	// Appending up to the hint does not allocate nodes one at a time.
+	allocs := testing.AllocsPerRun(10, func() {
+		b := NewListBuilderSized[int](hint)
+		for i := 0; i < hint; i++ {
+			b.Append(i)
+		}
+		b.List()
+	})
+	if allocs > 20 {
+		t.Fatalf("allocs=%v, expected at most 20", allocs)
+	}
+}
+
+func BenchmarkListBuilder_Sized(b *testing.B) {
+	const n = 100000
+
+	b.Run("Sized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewListBuilderSized[int](n)
+			for j := 0; j < n; j++ {
+				builder.Append(j)
+			}
+			builder.List()
+		}
+	})
+
+	b.Run("Unsized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewListBuilder[int]()
+			for j := 0; j < n; j++ {
+				builder.Append(j)
+			}
+			builder.List()
+		}
+	})
+}
+
+func BenchmarkListBuilder_Append(b *testing.B) {
+	b.ReportAllocs()
+	builder := NewListBuilder[int]()
+	for i := 0; i < b.N; i++ {
+		builder.Append(i)
+	}
+}
+
+func BenchmarkListBuilder_Prepend(b *testing.B) {
+	b.ReportAllocs()
+	builder := NewListBuilder[int]()
+	for i := 0; i < b.N; i++ {
+		builder.Prepend(i)
+	}
+}
+
+func BenchmarkListBuilder_Set(b *testing.B) {
+	const n = 10000
+
+	builder := NewListBuilder[int]()
+	for i := 0; i < 10000; i++ {
+		builder.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		builder.Set(i%n, i*10)
+	}
+}
+
+func ExampleList_Append() {
+	l := NewList[string]()
+	l = l.Append("foo")
+	l = l.Append("bar")
+	l = l.Append("baz")
+
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	fmt.Println(l.Get(2))
+	// Output:
+	// foo
+	// bar
+	// baz
+}
+
+func ExampleList_Prepend() {
+	l := NewList[string]()
+	l = l.Prepend("foo")
+	l = l.Prepend("bar")
+	l = l.Prepend("baz")
+
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	fmt.Println(l.Get(2))
+	// Output:
+	// baz
+	// bar
+	// foo
+}
+
+func ExampleList_Set() {
+	l := NewList[string]()
+	l = l.Append("foo")
+	l = l.Append("bar")
+	l = l.Set(1, "baz")
+
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	// Output:
+	// foo
+	// baz
+}
+
+func ExampleList_Slice() {
+	l := NewList[string]()
+	l = l.Append("foo")
+	l = l.Append("bar")
+	l = l.Append("baz")
+	l = l.Slice(1, 3)
+
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	// Output:
+	// bar
+	// baz
+}
+
+func ExampleList_Iterator() {
+	l := NewList[string]()
+	l = l.Append("foo")
+	l = l.Append("bar")
+	l = l.Append("baz")
+
+	itr := l.Iterator()
+	for !itr.Done() {
+		i, v := itr.Next()
+		fmt.Println(i, v)
+	}
+	// Output:
+	// 0 foo
+	// 1 bar
+	// 2 baz
+}
+
+func ExampleList_Iterator_reverse() {
+	l := NewList[string]()
+	l = l.Append("foo")
+	l = l.Append("bar")
+	l = l.Append("baz")
+
+	itr := l.Iterator()
+	itr.Last()
+	for !itr.Done() {
+		i, v := itr.Prev()
+		fmt.Println(i, v)
+	}
+	// Output:
+	// 2 baz
+	// 1 bar
+	// 0 foo
+}
+
+func ExampleListBuilder_Append() {
+	b := NewListBuilder[string]()
+	b.Append("foo")
+	b.Append("bar")
+	b.Append("baz")
+
+	l := b.List()
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	fmt.Println(l.Get(2))
+	// Output:
+	// foo
+	// bar
+	// baz
 }
 
 func ExampleListBuilder_Prepend() {
@@ -603,657 +1671,2150 @@ func ExampleListBuilder_Prepend() {
 	b.Prepend("bar")
 	b.Prepend("baz")
 
-	l := b.List()
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	fmt.Println(l.Get(2))
-	// Output:
-	// baz
-	// bar
-	// foo
+	l := b.List()
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	fmt.Println(l.Get(2))
+	// Output:
+	// baz
+	// bar
+	// foo
+}
+
+func ExampleListBuilder_Set() {
+	b := NewListBuilder[string]()
+	b.Append("foo")
+	b.Append("bar")
+	b.Set(1, "baz")
+
+	l := b.List()
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	// Output:
+	// foo
+	// baz
+}
+
+func ExampleListBuilder_Slice() {
+	b := NewListBuilder[string]()
+	b.Append("foo")
+	b.Append("bar")
+	b.Append("baz")
+	b.Slice(1, 3)
+
+	l := b.List()
+	fmt.Println(l.Get(0))
+	fmt.Println(l.Get(1))
+	// Output:
+	// bar
+	// baz
+}
+
+// Ensure node can support overwrites as it expands.
+func TestInternal_mapNode_Overwrite(t *testing.T) {
+	const n = 1000
+	var h defaultHasher[int]
+	var node mapNode[int, int] = &mapArrayNode[int, int]{}
+	for i := 0; i < n; i++ {
+		var resized bool
+		node = node.set(i, i, 0, h.Hash(i), &h, nil, &resized)
+		if !resized {
+			t.Fatal("expected resize")
+		}
+
+		// Overwrite every node.
+		for j := 0; j <= i; j++ {
+			var resized bool
+			node = node.set(j, i*j, 0, h.Hash(j), &h, nil, &resized)
+			if resized {
+				t.Fatalf("expected no resize: i=%d, j=%d", i, j)
+			}
+		}
+
+		// Verify not found at each branch type.
+		if _, _, ok := node.get(1000000, 0, h.Hash(1000000), &h); ok {
+			t.Fatal("expected no value")
+		}
+	}
+
+	// Verify all key/value pairs in map.
+	for i := 0; i < n; i++ {
+		if _, v, ok := node.get(i, 0, h.Hash(i), &h); !ok || v != i*(n-1) {
+			t.Fatalf("get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+}
+
+func TestInternal_mapArrayNode(t *testing.T) {
+	// Ensure 8 or fewer elements stays in an array node.
+	t.Run("Append", func(t *testing.T) {
+		var h defaultHasher[int]
+		n := &mapArrayNode[int, int]{}
+		for i := 0; i < 8; i++ {
+			var resized bool
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized).(*mapArrayNode[int, int])
+			if !resized {
+				t.Fatal("expected resize")
+			}
+
+			for j := 0; j < i; j++ {
+				if _, v, ok := n.get(j*10, 0, h.Hash(j*10), &h); !ok || v != j {
+					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
+				}
+			}
+		}
+	})
+
+	// Ensure 8 or fewer elements stays in an array node when inserted in reverse.
+	t.Run("Prepend", func(t *testing.T) {
+		var h defaultHasher[int]
+		n := &mapArrayNode[int, int]{}
+		for i := 7; i >= 0; i-- {
+			var resized bool
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized).(*mapArrayNode[int, int])
+			if !resized {
+				t.Fatal("expected resize")
+			}
+
+			for j := i; j <= 7; j++ {
+				if _, v, ok := n.get(j*10, 0, h.Hash(j*10), &h); !ok || v != j {
+					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
+				}
+			}
+		}
+	})
+
+	// Ensure array can transition between node types.
+	t.Run("Expand", func(t *testing.T) {
+		var h defaultHasher[int]
+		var n mapNode[int, int] = &mapArrayNode[int, int]{}
+		for i := 0; i < 100; i++ {
+			var resized bool
+			n = n.set(i, i, 0, h.Hash(i), &h, nil, &resized)
+			if !resized {
+				t.Fatal("expected resize")
+			}
+
+			for j := 0; j < i; j++ {
+				if _, v, ok := n.get(j, 0, h.Hash(j), &h); !ok || v != j {
+					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
+				}
+			}
+		}
+	})
+
+	// Ensure deleting elements returns the correct new node.
+	RunRandom(t, "Delete", func(t *testing.T, rand *rand.Rand) {
+		var h defaultHasher[int]
+		var n mapNode[int, int] = &mapArrayNode[int, int]{}
+		for i := 0; i < 8; i++ {
+			var resized bool
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized)
+		}
+
+		for _, i := range rand.Perm(8) {
+			var resized bool
+			n = n.delete(i*10, 0, h.Hash(i*10), &h, nil, &resized)
+		}
+		if n != nil {
+			t.Fatal("expected nil rand")
+		}
+	})
+}
+
+func TestInternal_mapValueNode(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		var h defaultHasher[int]
+		n := newMapValueNode(nil, h.Hash(2), 2, 3)
+		if _, v, ok := n.get(2, 0, h.Hash(2), &h); !ok {
+			t.Fatal("expected ok")
+		} else if v != 3 {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	})
+
+	t.Run("KeyEqual", func(t *testing.T) {
+		var h defaultHasher[int]
+		var resized bool
+		n := newMapValueNode(nil, h.Hash(2), 2, 3)
+		other := n.set(2, 4, 0, h.Hash(2), &h, nil, &resized).(*mapValueNode[int, int])
+		if other == n {
+			t.Fatal("expected new node")
+		} else if got, exp := other.keyHash, h.Hash(2); got != exp {
+			t.Fatalf("keyHash=%v, expected %v", got, exp)
+		} else if got, exp := other.key, 2; got != exp {
+			t.Fatalf("key=%v, expected %v", got, exp)
+		} else if got, exp := other.value, 4; got != exp {
+			t.Fatalf("value=%v, expected %v", got, exp)
+		} else if resized {
+			t.Fatal("unexpected resize")
+		}
+	})
+
+	t.Run("KeyHashEqual", func(t *testing.T) {
+		h := &mockHasher[int]{
+			hash:  func(value int) uint32 { return 1 },
+			equal: func(a, b int) bool { return a == b },
+		}
+		var resized bool
+		n := newMapValueNode(nil, h.Hash(2), 2, 3)
+		other := n.set(4, 5, 0, h.Hash(4), h, nil, &resized).(*mapHashCollisionNode[int, int])
+		if got, exp := other.keyHash, h.Hash(2); got != exp {
+			t.Fatalf("keyHash=%v, expected %v", got, exp)
+		} else if got, exp := len(other.entries), 2; got != exp {
+			t.Fatalf("entries=%v, expected %v", got, exp)
+		} else if !resized {
+			t.Fatal("expected resize")
+		}
+		if got, exp := other.entries[0].key, 2; got != exp {
+			t.Fatalf("key[0]=%v, expected %v", got, exp)
+		} else if got, exp := other.entries[0].value, 3; got != exp {
+			t.Fatalf("value[0]=%v, expected %v", got, exp)
+		}
+		if got, exp := other.entries[1].key, 4; got != exp {
+			t.Fatalf("key[1]=%v, expected %v", got, exp)
+		} else if got, exp := other.entries[1].value, 5; got != exp {
+			t.Fatalf("value[1]=%v, expected %v", got, exp)
+		}
+	})
+
+	t.Run("MergeNode", func(t *testing.T) {
+		// Inserting into a node with a different index in the mask should split into a bitmap node.
+		t.Run("NoConflict", func(t *testing.T) {
+			var h defaultHasher[int]
+			var resized bool
+			n := newMapValueNode(nil, h.Hash(2), 2, 3)
+			other := n.set(4, 5, 0, h.Hash(4), &h, nil, &resized).(*mapBitmapIndexedNode[int, int])
+			if got, exp := other.bitmap, uint32(0x14); got != exp {
+				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
+			} else if got, exp := len(other.nodes), 2; got != exp {
+				t.Fatalf("nodes=%v, expected %v", got, exp)
+			} else if !resized {
+				t.Fatal("expected resize")
+			}
+			if node, ok := other.nodes[0].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
+			} else if got, exp := node.key, 2; got != exp {
+				t.Fatalf("key[0]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 3; got != exp {
+				t.Fatalf("value[0]=%v, expected %v", got, exp)
+			}
+			if node, ok := other.nodes[1].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[1]=%T, unexpected type", other.nodes[1])
+			} else if got, exp := node.key, 4; got != exp {
+				t.Fatalf("key[1]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 5; got != exp {
+				t.Fatalf("value[1]=%v, expected %v", got, exp)
+			}
+
+			// Ensure both values can be read.
+			if _, v, ok := other.get(2, 0, h.Hash(2), &h); !ok || v != 3 {
+				t.Fatalf("Get(2)=<%v,%v>", v, ok)
+			} else if _, v, ok := other.get(4, 0, h.Hash(4), &h); !ok || v != 5 {
+				t.Fatalf("Get(4)=<%v,%v>", v, ok)
+			}
+		})
+
+		// Reversing the nodes from NoConflict should yield the same result.
+		t.Run("NoConflictReverse", func(t *testing.T) {
+			var h defaultHasher[int]
+			var resized bool
+			n := newMapValueNode(nil, h.Hash(4), 4, 5)
+			other := n.set(2, 3, 0, h.Hash(2), &h, nil, &resized).(*mapBitmapIndexedNode[int, int])
+			if got, exp := other.bitmap, uint32(0x14); got != exp {
+				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
+			} else if got, exp := len(other.nodes), 2; got != exp {
+				t.Fatalf("nodes=%v, expected %v", got, exp)
+			} else if !resized {
+				t.Fatal("expected resize")
+			}
+			if node, ok := other.nodes[0].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
+			} else if got, exp := node.key, 2; got != exp {
+				t.Fatalf("key[0]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 3; got != exp {
+				t.Fatalf("value[0]=%v, expected %v", got, exp)
+			}
+			if node, ok := other.nodes[1].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[1]=%T, unexpected type", other.nodes[1])
+			} else if got, exp := node.key, 4; got != exp {
+				t.Fatalf("key[1]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 5; got != exp {
+				t.Fatalf("value[1]=%v, expected %v", got, exp)
+			}
+
+			// Ensure both values can be read.
+			if _, v, ok := other.get(2, 0, h.Hash(2), &h); !ok || v != 3 {
+				t.Fatalf("Get(2)=<%v,%v>", v, ok)
+			} else if _, v, ok := other.get(4, 0, h.Hash(4), &h); !ok || v != 5 {
+				t.Fatalf("Get(4)=<%v,%v>", v, ok)
+			}
+		})
+
+		// Inserting a node with the same mask index should nest an additional level of bitmap nodes.
+		t.Run("Conflict", func(t *testing.T) {
+			h := &mockHasher[int]{
+				hash:  func(value int) uint32 { return uint32(value << 5) },
+				equal: func(a, b int) bool { return a == b },
+			}
+			var resized bool
+			n := newMapValueNode(nil, h.Hash(2), 2, 3)
+			other := n.set(4, 5, 0, h.Hash(4), h, nil, &resized).(*mapBitmapIndexedNode[int, int])
+			if got, exp := other.bitmap, uint32(0x01); got != exp { // mask is zero, expect first slot.
+				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
+			} else if got, exp := len(other.nodes), 1; got != exp {
+				t.Fatalf("nodes=%v, expected %v", got, exp)
+			} else if !resized {
+				t.Fatal("expected resize")
+			}
+			child, ok := other.nodes[0].(*mapBitmapIndexedNode[int, int])
+			if !ok {
+				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
+			}
+
+			if node, ok := child.nodes[0].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[0]=%T, unexpected type", child.nodes[0])
+			} else if got, exp := node.key, 2; got != exp {
+				t.Fatalf("key[0]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 3; got != exp {
+				t.Fatalf("value[0]=%v, expected %v", got, exp)
+			}
+			if node, ok := child.nodes[1].(*mapValueNode[int, int]); !ok {
+				t.Fatalf("node[1]=%T, unexpected type", child.nodes[1])
+			} else if got, exp := node.key, 4; got != exp {
+				t.Fatalf("key[1]=%v, expected %v", got, exp)
+			} else if got, exp := node.value, 5; got != exp {
+				t.Fatalf("value[1]=%v, expected %v", got, exp)
+			}
+
+			// Ensure both values can be read.
+			if _, v, ok := other.get(2, 0, h.Hash(2), h); !ok || v != 3 {
+				t.Fatalf("Get(2)=<%v,%v>", v, ok)
+			} else if _, v, ok := other.get(4, 0, h.Hash(4), h); !ok || v != 5 {
+				t.Fatalf("Get(4)=<%v,%v>", v, ok)
+			} else if _, v, ok := other.get(10, 0, h.Hash(10), h); ok {
+				t.Fatalf("Get(10)=<%v,%v>, expected no value", v, ok)
+			}
+		})
+	})
+}
+
+func TestMap_Get(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		if v, ok := m.Get(100); ok {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		}
+	})
+}
+
+func TestMap_Set(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		itr := m.Iterator()
+		if !itr.Done() {
+			t.Fatal("MapIterator.Done()=true, expected false")
+		} else if k, v, ok := itr.Next(); ok {
+			t.Fatalf("MapIterator.Next()=<%v,%v>, expected nil", k, v)
+		}
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		m = m.Set(100, "foo")
+		if v, ok := m.Get(100); !ok || v != "foo" {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("VerySmall", func(t *testing.T) {
+		const n = 6
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i+1)
+		}
+		for i := 0; i < n; i++ {
+			if v, ok := m.Get(i); !ok || v != i+1 {
+				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+			}
+		}
+
+		// NOTE: Array nodes store entries in insertion order.
+		itr := m.Iterator()
+		for i := 0; i < n; i++ {
+			if k, v, ok := itr.Next(); !ok || k != i || v != i+1 {
+				t.Fatalf("MapIterator.Next()=<%v,%v>, exp <%v,%v>", k, v, i, i+1)
+			}
+		}
+		if !itr.Done() {
+			t.Fatal("expected iterator done")
+		}
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		const n = 1000
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i+1)
+		}
+		for i := 0; i < n; i++ {
+			if v, ok := m.Get(i); !ok || v != i+1 {
+				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping: short")
+		}
+
+		const n = 1000000
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i+1)
+		}
+		for i := 0; i < n; i++ {
+			if v, ok := m.Get(i); !ok || v != i+1 {
+				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("StringKeys", func(t *testing.T) {
+		m := NewMap[string, string](nil)
+		m = m.Set("foo", "bar")
+		m = m.Set("baz", "bat")
+		m = m.Set("", "EMPTY")
+		if v, ok := m.Get("foo"); !ok || v != "bar" {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		} else if v, ok := m.Get("baz"); !ok || v != "bat" {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		} else if v, ok := m.Get(""); !ok || v != "EMPTY" {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		}
+		if v, ok := m.Get("no_such_key"); ok {
+			t.Fatalf("expected no value: <%v,%v>", v, ok)
+		}
+	})
+
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		m := NewTestMap()
+		for i := 0; i < 10000; i++ {
+			switch rand.Intn(2) {
+			case 1: // overwrite
+				m.Set(m.ExistingKey(rand), rand.Intn(10000))
+			default: // set new key
+				m.Set(m.NewKey(rand), rand.Intn(10000))
+			}
+		}
+		if err := m.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Ensure map can support overwrites as it expands.
+func TestMap_Overwrite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short mode")
+	}
+
+	const n = 10000
+	m := NewMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		// Set original value.
+		m = m.Set(i, i)
+
+		// Overwrite every node.
+		for j := 0; j <= i; j++ {
+			m = m.Set(j, i*j)
+		}
+	}
+
+	// Verify all key/value pairs in map.
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(i); !ok || v != i*(n-1) {
+			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+}
+
+func TestMap_Delete(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewMap[string, int](nil)
+		other := m.Delete("foo")
+		if m != other {
+			t.Fatal("expected same map")
+		}
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		m = m.Set(100, "foo")
+		if v, ok := m.Get(100); !ok || v != "foo" {
+			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		const n = 1000
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i+1)
+		}
+		for i := range rand.New(rand.NewSource(0)).Perm(n) {
+			m = m.Delete(i)
+		}
+		if m.Len() != 0 {
+			t.Fatalf("expected no elements, got %d", m.Len())
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping: short")
+		}
+		const n = 1000000
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i+1)
+		}
+		for i := range rand.New(rand.NewSource(0)).Perm(n) {
+			m = m.Delete(i)
+		}
+		if m.Len() != 0 {
+			t.Fatalf("expected no elements, got %d", m.Len())
+		}
+	})
+
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		m := NewTestMap()
+		for i := 0; i < 10000; i++ {
+			switch rand.Intn(8) {
+			case 0: // overwrite
+				m.Set(m.ExistingKey(rand), rand.Intn(10000))
+			case 1: // delete existing key
+				m.Delete(m.ExistingKey(rand))
+			case 2: // delete non-existent key.
+				m.Delete(m.NewKey(rand))
+			default: // set new key
+				m.Set(m.NewKey(rand), rand.Intn(10000))
+			}
+		}
+
+		// Delete all and verify they are gone.
+		keys := make([]int, len(m.keys))
+		copy(keys, m.keys)
+
+		for _, key := range keys {
+			m.Delete(key)
+		}
+		if err := m.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Ensure map works even with hash conflicts.
+func TestMap_LimitedHash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping: short")
+	}
+
+	t.Run("Immutable", func(t *testing.T) {
+		h := mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value)) % 0xFF },
+			equal: func(a, b int) bool { return a == b },
+		}
+		m := NewMap[int, int](&h)
+
+		rand := rand.New(rand.NewSource(0))
+		keys := rand.Perm(100000)
+		for _, i := range keys {
+			m = m.Set(i, i) // initial set
+		}
+		for i := range keys {
+			m = m.Set(i, i*2) // overwrite
+		}
+		if m.Len() != len(keys) {
+			t.Fatalf("unexpected len: %d", m.Len())
+		}
+
+		// Verify all key/value pairs in map.
+		for i := 0; i < m.Len(); i++ {
+			if v, ok := m.Get(i); !ok || v != i*2 {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+
+		// Verify iteration.
+		itr := m.Iterator()
+		for !itr.Done() {
+			if k, v, ok := itr.Next(); !ok || v != k*2 {
+				t.Fatalf("MapIterator.Next()=<%v,%v>, expected value %v", k, v, k*2)
+			}
+		}
+
+		// Verify not found works.
+		if _, ok := m.Get(10000000); ok {
+			t.Fatal("expected no value")
+		}
+
+		// Verify delete non-existent key works.
+		if other := m.Delete(10000000 + 1); m != other {
+			t.Fatal("expected no change")
+		}
+
+		// Remove all keys.
+		for _, key := range keys {
+			m = m.Delete(key)
+		}
+		if m.Len() != 0 {
+			t.Fatalf("unexpected size: %d", m.Len())
+		}
+	})
+
+	t.Run("Builder", func(t *testing.T) {
+		h := mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		b := NewMapBuilder[int, int](&h)
+
+		rand := rand.New(rand.NewSource(0))
+		keys := rand.Perm(100000)
+		for _, i := range keys {
+			b.Set(i, i) // initial set
+		}
+		for i := range keys {
+			b.Set(i, i*2) // overwrite
+		}
+		if b.Len() != len(keys) {
+			t.Fatalf("unexpected len: %d", b.Len())
+		}
+
+		// Verify all key/value pairs in map.
+		for i := 0; i < b.Len(); i++ {
+			if v, ok := b.Get(i); !ok || v != i*2 {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+
+		// Verify iteration.
+		itr := b.Iterator()
+		for !itr.Done() {
+			if k, v, ok := itr.Next(); !ok || v != k*2 {
+				t.Fatalf("MapIterator.Next()=<%v,%v>, expected value %v", k, v, k*2)
+			}
+		}
+
+		// Verify not found works.
+		if _, ok := b.Get(10000000); ok {
+			t.Fatal("expected no value")
+		}
+
+		// Remove all keys.
+		for _, key := range keys {
+			b.Delete(key)
+		}
+		if b.Len() != 0 {
+			t.Fatalf("unexpected size: %d", b.Len())
+		}
+	})
+}
+
+func TestMap_Filter(t *testing.T) {
+	const n = 10000
+	m := NewMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		m = m.Set(i, i*2)
+	}
+
+	t.Run("Subset", func(t *testing.T) {
+		other := m.Filter(func(k, v int) bool { return k%1000 == 0 })
+		if other.Len() != 10 {
+			t.Fatalf("Len()=%d, expected 10", other.Len())
+		}
+		for i := 0; i < n; i += 1000 {
+			if v, ok := other.Get(i); !ok || v != i*2 {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+		if _, ok := other.Get(1); ok {
+			t.Fatal("expected key to be filtered")
+		}
+		if m.Len() != n {
+			t.Fatalf("unexpected mutation of original map: Len()=%d", m.Len())
+		}
+		for i := 0; i < n; i++ {
+			if v, ok := m.Get(i); !ok || v != i*2 {
+				t.Fatalf("unexpected mutation of original map: Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		if other := m.Filter(func(k, v int) bool { return true }); other != m {
+			t.Fatal("expected original map when all pairs are retained")
+		}
+	})
+
+	t.Run("None", func(t *testing.T) {
+		if other := m.Filter(func(k, v int) bool { return false }); other.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", other.Len())
+		}
+	})
+}
+
+func TestMap_Count(t *testing.T) {
+	m := NewMap[int, int](nil)
+	for i := 0; i < 10000; i++ {
+		m = m.Set(i, i*3)
+	}
+	isEven := func(k, v int) bool { return v%2 == 0 }
+	if got, exp := m.Count(isEven), 5000; got != exp {
+		t.Fatalf("Count()=%d, exp %d", got, exp)
+	} else if got := m.Count(func(k, v int) bool { return false }); got != 0 {
+		t.Fatalf("Count()=%d, exp 0", got)
+	} else if got := NewMap[int, int](nil).Count(isEven); got != 0 {
+		t.Fatalf("Count()=%d, exp 0", got)
+	}
+
+	t.Run("MatchesFilter", func(t *testing.T) {
+		for _, mod := range []int{1, 3, 7, 1000, 20000} {
+			pred := func(k, v int) bool { return k%mod == 0 }
+			if got, exp := m.Count(pred), m.Filter(pred).Len(); got != exp {
+				t.Fatalf("mod %d: Count()=%d, Filter().Len()=%d", mod, got, exp)
+			}
+		}
+	})
+}
+
+func TestMap_Collect(t *testing.T) {
+	const n = 100000
+	b := NewMapBuilder[int, int](nil)
+	for i := 0; i < n; i++ {
+		b.Set(i, i*3)
+	}
+	m := b.Map()
+	isEven := func(k, v int) bool { return v%2 == 0 }
+
+	keys, values := m.CollectKeys(isEven), m.CollectValues(isEven)
+	if len(keys) != n/2 || len(values) != n/2 {
+		t.Fatalf("len(keys)=%d, len(values)=%d, expected %d", len(keys), len(values), n/2)
+	}
+	for i := range keys {
+		if keys[i]%2 != 0 || values[i] != keys[i]*3 {
+			t.Fatalf("unexpected pair at %d: <%d,%d>", i, keys[i], values[i])
+		}
+	}
+	if got := m.CollectKeys(func(k, v int) bool { return false }); len(got) != 0 {
+		t.Fatalf("CollectKeys()=%v, expected none", got)
+	} else if got := NewMap[int, int](nil).CollectValues(isEven); len(got) != 0 {
+		t.Fatalf("CollectValues()=%v, expected none", got)
+	}
+}
+
+func TestMap_DeleteIf(t *testing.T) {
+	// Sessions keyed by ID with an expiry timestamp.
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMap[int, time.Time](nil)
+	for id := 0; id < 100; id++ {
+		m = m.Set(id, now.Add(time.Duration(id-50)*time.Minute))
+	}
+
+	expired := func(id int, expiry time.Time) bool { return !expiry.After(now) }
+	other := m.DeleteIf(expired)
+	if got, exp := other.Len(), 49; got != exp {
+		t.Fatalf("Len()=%d, exp %d", got, exp)
+	}
+	for id := 0; id < 100; id++ {
+		if _, ok := other.Get(id); ok != (id > 50) {
+			t.Fatalf("Get(%d) ok=%v", id, ok)
+		}
+	}
+	if got, exp := m.Len(), 100; got != exp {
+		t.Fatalf("original Len()=%d, exp %d", got, exp)
+	}
+
+	if other.DeleteIf(expired) != other {
+		t.Fatal("expected original map when nothing is removed")
+	}
+}
+
+func TestMap_Partition(t *testing.T) {
+	h := &mockHasher[int]{
+		hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+		equal: func(a, b int) bool { return a == b },
+	}
+	m := NewMap[int, string](h)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, strconv.Itoa(i))
+	}
+
+	matched, rest := m.Partition(func(k int, v string) bool { return k%3 == 0 })
+	if got, exp := matched.Len()+rest.Len(), m.Len(); got != exp {
+		t.Fatalf("matched.Len()+rest.Len()=%d, exp %d", got, exp)
+	} else if got, exp := matched.Len(), 334; got != exp {
+		t.Fatalf("matched.Len()=%d, exp %d", got, exp)
+	} else if matched.Hasher() != h || rest.Hasher() != h {
+		t.Fatal("expected both maps to use original hasher")
+	}
+	for i := 0; i < 1000; i++ {
+		_, inMatched := matched.Get(i)
+		_, inRest := rest.Get(i)
+		if inMatched == inRest {
+			t.Fatalf("key %d: matched=%v, rest=%v", i, inMatched, inRest)
+		} else if inMatched != (i%3 == 0) {
+			t.Fatalf("key %d routed incorrectly", i)
+		}
+	}
+
+	t.Run("All", func(t *testing.T) {
+		matched, rest := m.Partition(func(k int, v string) bool { return true })
+		if matched != m {
+			t.Fatal("expected original map for matched")
+		} else if rest.Len() != 0 {
+			t.Fatalf("rest.Len()=%d, exp 0", rest.Len())
+		}
+	})
+}
+
+func TestMap_MapValues(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewMap[int, int](nil).MapValues(func(k, v int) int { return v + 1 })
+		if m.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", m.Len())
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		const n = 10000
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i)
+		}
+
+		other := m.MapValues(func(k, v int) int { return k + v*10 })
+		if other.Len() != n {
+			t.Fatalf("Len()=%d, expected %d", other.Len(), n)
+		}
+		for i := 0; i < n; i++ {
+			if v, ok := other.Get(i); !ok || v != i*11 {
+				t.Fatalf("Get(%d)=<%v,%v>, expected %d", i, v, ok, i*11)
+			}
+			if v, ok := m.Get(i); !ok || v != i {
+				t.Fatalf("unexpected mutation of original map: Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+
+		// Ensure the new map can continue to be updated.
+		other = other.Set(n, 0).Delete(0)
+		if other.Len() != n {
+			t.Fatalf("Len()=%d, expected %d", other.Len(), n)
+		} else if m.Len() != n {
+			t.Fatalf("unexpected mutation of original map: Len()=%d", m.Len())
+		}
+	})
+
+	t.Run("LimitedHash", func(t *testing.T) {
+		h := mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value)) % 0xFF },
+			equal: func(a, b int) bool { return a == b },
+		}
+		m := NewMap[int, string](&h)
+		for i := 0; i < 2000; i++ {
+			m = m.Set(i, "x")
+		}
+		other := m.MapValues(func(k int, v string) string { return fmt.Sprint(k) })
+		for i := 0; i < 2000; i++ {
+			if v, ok := other.Get(i); !ok || v != fmt.Sprint(i) {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+	})
 }
 
-func ExampleListBuilder_Set() {
-	b := NewListBuilder[string]()
-	b.Append("foo")
-	b.Append("bar")
-	b.Set(1, "baz")
+func TestMap_Merge(t *testing.T) {
+	a := NewMap[string, int](nil).Set("foo", 1).Set("bar", 2)
+	b := NewMap[string, int](nil).Set("bar", 10).Set("baz", 20)
+
+	t.Run("Resolve", func(t *testing.T) {
+		m := a.Merge(b, func(k string, x, y int) int { return x + y })
+		if m.Len() != 3 {
+			t.Fatalf("Len()=%d, expected 3", m.Len())
+		}
+		for k, exp := range map[string]int{"foo": 1, "bar": 12, "baz": 20} {
+			if v, ok := m.Get(k); !ok || v != exp {
+				t.Fatalf("Get(%q)=<%v,%v>, expected %d", k, v, ok, exp)
+			}
+		}
+		if v, _ := a.Get("bar"); v != 2 || a.Len() != 2 {
+			t.Fatal("unexpected mutation of receiver")
+		} else if v, _ := b.Get("bar"); v != 10 || b.Len() != 2 {
+			t.Fatal("unexpected mutation of other map")
+		}
+	})
 
-	l := b.List()
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	// Output:
-	// foo
-	// baz
+	t.Run("NilResolve", func(t *testing.T) {
+		m := a.Merge(b, nil)
+		if v, ok := m.Get("bar"); !ok || v != 10 {
+			t.Fatalf("Get(bar)=<%v,%v>, expected other to win", v, ok)
+		} else if v, ok := m.Get("foo"); !ok || v != 1 {
+			t.Fatalf("Get(foo)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if m := a.Merge(NewMap[string, int](nil), nil); m != a {
+			t.Fatal("expected receiver when merging an empty map")
+		}
+		if m := NewMap[string, int](nil).Merge(b, nil); m != b {
+			t.Fatal("expected other map when merging into an empty map")
+		}
+		if m := a.Merge(nil, nil); m != a {
+			t.Fatal("expected receiver when merging a nil map")
+		}
+		if m := NewMap[string, int](nil).Merge(nil, nil); m == nil || m.Len() != 0 {
+			t.Fatal("expected empty receiver when merging a nil map into an empty map")
+		}
+	})
 }
 
-func ExampleListBuilder_Slice() {
-	b := NewListBuilder[string]()
-	b.Append("foo")
-	b.Append("bar")
-	b.Append("baz")
-	b.Slice(1, 3)
+func TestMap_ForEach(t *testing.T) {
+	m := NewMap[int, int](nil)
+	for i := 1; i <= 100; i++ {
+		m = m.Set(i, i*2)
+	}
 
-	l := b.List()
-	fmt.Println(l.Get(0))
-	fmt.Println(l.Get(1))
-	// Output:
-	// bar
-	// baz
+	var sum int
+	m.ForEach(func(k, v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 10100 {
+		t.Fatalf("sum=%d, expected 10100", sum)
+	}
+
+	var n int
+	m.ForEach(func(k, v int) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Fatalf("visited %d pairs, expected 10", n)
+	}
 }
 
-// Ensure node can support overwrites as it expands.
-func TestInternal_mapNode_Overwrite(t *testing.T) {
-	const n = 1000
-	var h defaultHasher[int]
-	var node mapNode[int, int] = &mapArrayNode[int, int]{}
-	for i := 0; i < n; i++ {
-		var resized bool
-		node = node.set(i, i, 0, h.Hash(i), &h, false, &resized)
-		if !resized {
-			t.Fatal("expected resize")
-		}
+func TestMapDiff(t *testing.T) {
+	prev := NewMap[string, string](nil).
+		Set("host", "localhost").
+		Set("port", "8080").
+		Set("debug", "true").
+		Set("timeout", "30s")
+	next := prev.
+		Set("port", "9090").
+		Set("timeout", "30s").
+		Delete("debug").
+		Set("tls", "on")
+
+	eq := func(a, b string) bool { return a == b }
+	added, removed, changed := MapDiff(prev, next, eq)
+	if got := added.String(); got != "Map{tls:on}" {
+		t.Fatalf("added=%s", got)
+	} else if got := removed.String(); got != "Map{debug:true}" {
+		t.Fatalf("removed=%s", got)
+	} else if got := changed.String(); got != "Map{port:9090}" {
+		t.Fatalf("changed=%s", got)
+	}
 
-		// Overwrite every node.
-		for j := 0; j <= i; j++ {
-			var resized bool
-			node = node.set(j, i*j, 0, h.Hash(j), &h, false, &resized)
-			if resized {
-				t.Fatalf("expected no resize: i=%d, j=%d", i, j)
-			}
+	// The deltas partition the keys that differ and reproduce next from prev.
+	result := prev
+	for _, m := range []*Map[string, string]{added, changed} {
+		itr := m.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			result = result.Set(k, v)
 		}
+	}
+	itr := removed.Iterator()
+	for !itr.Done() {
+		k, _, _ := itr.Next()
+		result = result.Delete(k)
+	}
+	if !MapEqual(result, next) {
+		t.Fatalf("applying diff=%s, exp %s", result, next)
+	}
 
-		// Verify not found at each branch type.
-		if _, ok := node.get(1000000, 0, h.Hash(1000000), &h); ok {
-			t.Fatal("expected no value")
-		}
+	if added, removed, changed := MapDiff(prev, prev, eq); added.Len()+removed.Len()+changed.Len() != 0 {
+		t.Fatal("expected no differences between identical maps")
 	}
 
-	// Verify all key/value pairs in map.
-	for i := 0; i < n; i++ {
-		if v, ok := node.get(i, 0, h.Hash(i), &h); !ok || v != i*(n-1) {
-			t.Fatalf("get(%d)=<%v,%v>", i, v, ok)
+	t.Run("Nil", func(t *testing.T) {
+		added, removed, changed := MapDiff(nil, next, eq)
+		if !MapEqual(added, next) || removed.Len() != 0 || changed.Len() != 0 {
+			t.Fatalf("MapDiff(nil, next)=<%s,%s,%s>", added, removed, changed)
+		} else if added.hasher != next.hasher {
+			t.Fatal("expected added to use the hasher of next")
 		}
-	}
-}
 
-func TestInternal_mapArrayNode(t *testing.T) {
-	// Ensure 8 or fewer elements stays in an array node.
-	t.Run("Append", func(t *testing.T) {
-		var h defaultHasher[int]
-		n := &mapArrayNode[int, int]{}
-		for i := 0; i < 8; i++ {
-			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized).(*mapArrayNode[int, int])
-			if !resized {
-				t.Fatal("expected resize")
-			}
+		added, removed, changed = MapDiff(prev, nil, eq)
+		if added.Len() != 0 || !MapEqual(removed, prev) || changed.Len() != 0 {
+			t.Fatalf("MapDiff(prev, nil)=<%s,%s,%s>", added, removed, changed)
+		}
 
-			for j := 0; j < i; j++ {
-				if v, ok := n.get(j*10, 0, h.Hash(j*10), &h); !ok || v != j {
-					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
-				}
-			}
+		added, removed, changed = MapDiff[string, string](nil, nil, eq)
+		if added.Len()+removed.Len()+changed.Len() != 0 {
+			t.Fatal("expected no differences between nil maps")
 		}
 	})
+}
 
-	// Ensure 8 or fewer elements stays in an array node when inserted in reverse.
-	t.Run("Prepend", func(t *testing.T) {
-		var h defaultHasher[int]
-		n := &mapArrayNode[int, int]{}
-		for i := 7; i >= 0; i-- {
-			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized).(*mapArrayNode[int, int])
-			if !resized {
-				t.Fatal("expected resize")
-			}
-
-			for j := i; j <= 7; j++ {
-				if v, ok := n.get(j*10, 0, h.Hash(j*10), &h); !ok || v != j {
-					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
-				}
+func TestMapInvert(t *testing.T) {
+	t.Run("Bijective", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, fmt.Sprint(i))
+		}
+		other := MapInvert(m, nil)
+		if other.Len() != m.Len() {
+			t.Fatalf("Len()=%d, exp %d", other.Len(), m.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			if k, ok := other.Get(fmt.Sprint(i)); !ok || k != i {
+				t.Fatalf("Get(%d)=<%v,%v>", i, k, ok)
 			}
 		}
 	})
 
-	// Ensure array can transition between node types.
-	t.Run("Expand", func(t *testing.T) {
-		var h defaultHasher[int]
-		var n mapNode[int, int] = &mapArrayNode[int, int]{}
+	t.Run("Collision", func(t *testing.T) {
+		m := NewMap[int, int](nil)
 		for i := 0; i < 100; i++ {
-			var resized bool
-			n = n.set(i, i, 0, h.Hash(i), &h, false, &resized)
-			if !resized {
-				t.Fatal("expected resize")
-			}
+			m = m.Set(i, i%10)
+		}
 
-			for j := 0; j < i; j++ {
-				if v, ok := n.get(j, 0, h.Hash(j), &h); !ok || v != j {
-					t.Fatalf("get(%d)=<%v,%v>", j, v, ok)
-				}
+		// The last key for each value in iteration order wins.
+		exp := make(map[int]int)
+		itr := m.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			exp[v] = k
+		}
+
+		other := MapInvert(m, nil)
+		if other.Len() != 10 {
+			t.Fatalf("Len()=%d, exp 10", other.Len())
+		}
+		for v, k := range exp {
+			if got, ok := other.Get(v); !ok || got != k {
+				t.Fatalf("Get(%d)=<%v,%v>, exp %d", v, got, ok, k)
 			}
 		}
 	})
 
-	// Ensure deleting elements returns the correct new node.
-	RunRandom(t, "Delete", func(t *testing.T, rand *rand.Rand) {
-		var h defaultHasher[int]
-		var n mapNode[int, int] = &mapArrayNode[int, int]{}
-		for i := 0; i < 8; i++ {
-			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized)
+	t.Run("Empty", func(t *testing.T) {
+		if other := MapInvert(NewMap[int, int](nil), nil); other.Len() != 0 {
+			t.Fatalf("Len()=%d, exp 0", other.Len())
 		}
+	})
+}
 
-		for _, i := range rand.Perm(8) {
-			var resized bool
-			n = n.delete(i*10, 0, h.Hash(i*10), &h, false, &resized)
-		}
-		if n != nil {
-			t.Fatal("expected nil rand")
+func TestMap_Keys(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		if got := m.Keys().Len(); got != 0 {
+			t.Fatalf("Keys().Len()=%d, exp 0", got)
 		}
 	})
-}
 
-func TestInternal_mapValueNode(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
-		var h defaultHasher[int]
-		n := newMapValueNode(h.Hash(2), 2, 3)
-		if v, ok := n.get(2, 0, h.Hash(2), &h); !ok {
-			t.Fatal("expected ok")
-		} else if v != 3 {
-			t.Fatalf("unexpected value: %v", v)
+		m := NewMap[int, string](nil)
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, strconv.Itoa(i))
 		}
-	})
 
-	t.Run("KeyEqual", func(t *testing.T) {
-		var h defaultHasher[int]
-		var resized bool
-		n := newMapValueNode(h.Hash(2), 2, 3)
-		other := n.set(2, 4, 0, h.Hash(2), &h, false, &resized).(*mapValueNode[int, int])
-		if other == n {
-			t.Fatal("expected new node")
-		} else if got, exp := other.keyHash, h.Hash(2); got != exp {
-			t.Fatalf("keyHash=%v, expected %v", got, exp)
-		} else if got, exp := other.key, 2; got != exp {
-			t.Fatalf("key=%v, expected %v", got, exp)
-		} else if got, exp := other.value, 4; got != exp {
-			t.Fatalf("value=%v, expected %v", got, exp)
-		} else if resized {
-			t.Fatal("unexpected resize")
+		keys := m.Keys()
+		if got, exp := keys.Len(), m.Len(); got != exp {
+			t.Fatalf("Keys().Len()=%d, exp %d", got, exp)
+		}
+		for i := 0; i < 1000; i++ {
+			if !keys.Has(i) {
+				t.Fatalf("expected key %d in set", i)
+			}
 		}
 	})
 
-	t.Run("KeyHashEqual", func(t *testing.T) {
+	t.Run("Hasher", func(t *testing.T) {
 		h := &mockHasher[int]{
-			hash:  func(value int) uint32 { return 1 },
+			hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
 			equal: func(a, b int) bool { return a == b },
 		}
-		var resized bool
-		n := newMapValueNode(h.Hash(2), 2, 3)
-		other := n.set(4, 5, 0, h.Hash(4), h, false, &resized).(*mapHashCollisionNode[int, int])
-		if got, exp := other.keyHash, h.Hash(2); got != exp {
-			t.Fatalf("keyHash=%v, expected %v", got, exp)
-		} else if got, exp := len(other.entries), 2; got != exp {
-			t.Fatalf("entries=%v, expected %v", got, exp)
-		} else if !resized {
-			t.Fatal("expected resize")
-		}
-		if got, exp := other.entries[0].key, 2; got != exp {
-			t.Fatalf("key[0]=%v, expected %v", got, exp)
-		} else if got, exp := other.entries[0].value, 3; got != exp {
-			t.Fatalf("value[0]=%v, expected %v", got, exp)
-		}
-		if got, exp := other.entries[1].key, 4; got != exp {
-			t.Fatalf("key[1]=%v, expected %v", got, exp)
-		} else if got, exp := other.entries[1].value, 5; got != exp {
-			t.Fatalf("value[1]=%v, expected %v", got, exp)
+		m := NewMap[int, string](h).Set(1, "foo")
+		if keys := m.Keys(); keys.m.hasher != h {
+			t.Fatal("expected set to use map hasher")
 		}
 	})
+}
 
-	t.Run("MergeNode", func(t *testing.T) {
-		// Inserting into a node with a different index in the mask should split into a bitmap node.
-		t.Run("NoConflict", func(t *testing.T) {
-			var h defaultHasher[int]
-			var resized bool
-			n := newMapValueNode(h.Hash(2), 2, 3)
-			other := n.set(4, 5, 0, h.Hash(4), &h, false, &resized).(*mapBitmapIndexedNode[int, int])
-			if got, exp := other.bitmap, uint32(0x14); got != exp {
-				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
-			} else if got, exp := len(other.nodes), 2; got != exp {
-				t.Fatalf("nodes=%v, expected %v", got, exp)
-			} else if !resized {
-				t.Fatal("expected resize")
-			}
-			if node, ok := other.nodes[0].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
-			} else if got, exp := node.key, 2; got != exp {
-				t.Fatalf("key[0]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 3; got != exp {
-				t.Fatalf("value[0]=%v, expected %v", got, exp)
-			}
-			if node, ok := other.nodes[1].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[1]=%T, unexpected type", other.nodes[1])
-			} else if got, exp := node.key, 4; got != exp {
-				t.Fatalf("key[1]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 5; got != exp {
-				t.Fatalf("value[1]=%v, expected %v", got, exp)
-			}
+func TestMap_Values(t *testing.T) {
+	m := NewMap[string, int](nil)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(strconv.Itoa(i), i%10)
+	}
 
-			// Ensure both values can be read.
-			if v, ok := other.get(2, 0, h.Hash(2), &h); !ok || v != 3 {
-				t.Fatalf("Get(2)=<%v,%v>", v, ok)
-			} else if v, ok := other.get(4, 0, h.Hash(4), &h); !ok || v != 5 {
-				t.Fatalf("Get(4)=<%v,%v>", v, ok)
-			}
-		})
+	values := m.Values()
+	if got, exp := values.Len(), m.Len(); got != exp {
+		t.Fatalf("Values().Len()=%d, exp %d", got, exp)
+	}
 
-		// Reversing the nodes from NoConflict should yield the same result.
-		t.Run("NoConflictReverse", func(t *testing.T) {
-			var h defaultHasher[int]
-			var resized bool
-			n := newMapValueNode(h.Hash(4), 4, 5)
-			other := n.set(2, 3, 0, h.Hash(2), &h, false, &resized).(*mapBitmapIndexedNode[int, int])
-			if got, exp := other.bitmap, uint32(0x14); got != exp {
-				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
-			} else if got, exp := len(other.nodes), 2; got != exp {
-				t.Fatalf("nodes=%v, expected %v", got, exp)
-			} else if !resized {
-				t.Fatal("expected resize")
-			}
-			if node, ok := other.nodes[0].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
-			} else if got, exp := node.key, 2; got != exp {
-				t.Fatalf("key[0]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 3; got != exp {
-				t.Fatalf("value[0]=%v, expected %v", got, exp)
-			}
-			if node, ok := other.nodes[1].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[1]=%T, unexpected type", other.nodes[1])
-			} else if got, exp := node.key, 4; got != exp {
-				t.Fatalf("key[1]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 5; got != exp {
-				t.Fatalf("value[1]=%v, expected %v", got, exp)
-			}
+	// Duplicate values are retained so each value should appear 100 times.
+	counts := make(map[int]int)
+	itr := values.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		counts[v]++
+	}
+	for i := 0; i < 10; i++ {
+		if got, exp := counts[i], 100; got != exp {
+			t.Fatalf("count(%d)=%d, exp %d", i, got, exp)
+		}
+	}
+}
+
+func TestMap_Hasher(t *testing.T) {
+	h := &mockHasher[int]{
+		hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+		equal: func(a, b int) bool { return a == b },
+	}
+	if got := NewMap[int, int](h).Set(1, 1).Hasher(); got != h {
+		t.Fatalf("Hasher()=%v, expected %v", got, h)
+	}
+
+	m := NewMap[int, int](nil)
+	if got := m.Hasher(); got != nil {
+		t.Fatalf("Hasher()=%v, expected nil", got)
+	} else if got := m.Set(1, 1).Hasher(); got == nil {
+		t.Fatal("expected default hasher after Set")
+	}
+}
 
-			// Ensure both values can be read.
-			if v, ok := other.get(2, 0, h.Hash(2), &h); !ok || v != 3 {
-				t.Fatalf("Get(2)=<%v,%v>", v, ok)
-			} else if v, ok := other.get(4, 0, h.Hash(4), &h); !ok || v != 5 {
-				t.Fatalf("Get(4)=<%v,%v>", v, ok)
-			}
-		})
+func TestMap_Entries(t *testing.T) {
+	m := NewMap[int, string](nil)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, fmt.Sprint(i))
+	}
 
-		// Inserting a node with the same mask index should nest an additional level of bitmap nodes.
-		t.Run("Conflict", func(t *testing.T) {
-			h := &mockHasher[int]{
-				hash:  func(value int) uint32 { return uint32(value << 5) },
-				equal: func(a, b int) bool { return a == b },
-			}
-			var resized bool
-			n := newMapValueNode(h.Hash(2), 2, 3)
-			other := n.set(4, 5, 0, h.Hash(4), h, false, &resized).(*mapBitmapIndexedNode[int, int])
-			if got, exp := other.bitmap, uint32(0x01); got != exp { // mask is zero, expect first slot.
-				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
-			} else if got, exp := len(other.nodes), 1; got != exp {
-				t.Fatalf("nodes=%v, expected %v", got, exp)
-			} else if !resized {
-				t.Fatal("expected resize")
+	entries := m.Entries()
+	if got, exp := entries.Len(), m.Len(); got != exp {
+		t.Fatalf("Entries().Len()=%d, exp %d", got, exp)
+	}
+
+	b := NewMapBuilder[int, string](nil)
+	itr := entries.Iterator()
+	for !itr.Done() {
+		_, e := itr.Next()
+		b.Set(e.Key, e.Value)
+	}
+	if other := b.Map(); !MapEqual(other, m) {
+		t.Fatal("expected entries to rebuild the original map")
+	} else if got := NewMap[int, string](nil).Entries().Len(); got != 0 {
+		t.Fatalf("Entries().Len()=%d, exp 0", got)
+	}
+}
+
+func TestMap_String(t *testing.T) {
+	if got, exp := NewMap[string, int](nil).String(), "Map{}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+
+	// Map order is unspecified so compare the sorted pairs.
+	got := NewMap[string, int](nil).Set("a", 1).Set("b", 2).Set("c", 3).String()
+	if !strings.HasPrefix(got, "Map{") || !strings.HasSuffix(got, "}") {
+		t.Fatalf("unexpected format: %q", got)
+	}
+	pairs := strings.Split(strings.TrimSuffix(strings.TrimPrefix(got, "Map{"), "}"), ", ")
+	sort.Strings(pairs)
+	if got, exp := strings.Join(pairs, ","), "a:1,b:2,c:3"; got != exp {
+		t.Fatalf("unexpected pairs: %q, expected %q", got, exp)
+	}
+
+	m := NewMap[int, int](nil)
+	for i := 0; i < 150; i++ {
+		m = m.Set(i, i)
+	}
+	if got := m.String(); !strings.HasSuffix(got, ", ...}") || strings.Count(got, ":") != 100 {
+		t.Fatalf("unexpected truncated output: %q", got)
+	}
+}
+
+func TestMap_GetDefault(t *testing.T) {
+	m := NewMap[string, int](nil).Set("foo", 1).Set("zero", 0)
+	if got, exp := m.GetDefault("foo", -1), 1; got != exp {
+		t.Fatalf("GetDefault()=%d, exp %d", got, exp)
+	} else if got, exp := m.GetDefault("zero", -1), 0; got != exp {
+		t.Fatalf("GetDefault()=%d, exp %d", got, exp)
+	} else if got, exp := m.GetDefault("bar", -1), -1; got != exp {
+		t.Fatalf("GetDefault()=%d, exp %d", got, exp)
+	} else if got, exp := NewMap[string, int](nil).GetDefault("foo", -1), -1; got != exp {
+		t.Fatalf("GetDefault()=%d, exp %d", got, exp)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		for name, m := range map[string]*Map[string, int]{"Zero": {}, "Nil": nil} {
+			if m.Len() != 0 {
+				t.Fatalf("%s: Len()=%d", name, m.Len())
+			} else if _, ok := m.Get("a"); ok {
+				t.Fatalf("%s: expected Get() to fail", name)
+			} else if _, _, ok := m.GetEntry("a"); ok {
+				t.Fatalf("%s: expected GetEntry() to fail", name)
+			} else if itr := m.Iterator(); !itr.Done() {
+				t.Fatalf("%s: expected iterator to be done", name)
+			} else if _, _, ok := itr.Next(); ok {
+				t.Fatalf("%s: expected Next() to fail", name)
+			} else if itr.Seek("a"); !itr.Done() {
+				t.Fatalf("%s: expected Seek() to leave iterator done", name)
+			} else if m.Delete("a") != m {
+				t.Fatalf("%s: expected Delete() to return the original map", name)
 			}
-			child, ok := other.nodes[0].(*mapBitmapIndexedNode[int, int])
-			if !ok {
-				t.Fatalf("node[0]=%T, unexpected type", other.nodes[0])
+
+			pred := func(k string, v int) bool { return true }
+			if m.Hasher() != nil {
+				t.Fatalf("%s: expected nil Hasher()", name)
+			} else if m.Keys().Len() != 0 {
+				t.Fatalf("%s: Keys().Len()=%d", name, m.Keys().Len())
+			} else if m.Filter(pred) != m || m.DeleteIf(pred) != m {
+				t.Fatalf("%s: expected Filter() to return the original map", name)
+			} else if matched, rest := m.Partition(pred); matched.Len() != 0 || rest.Len() != 0 {
+				t.Fatalf("%s: Partition() lengths=%d,%d", name, matched.Len(), rest.Len())
+			} else if m.MapValues(func(k string, v int) int { return v }).Len() != 0 {
+				t.Fatalf("%s: expected MapValues() to be empty", name)
+			} else if m.Stats() != (MapStats{}) {
+				t.Fatalf("%s: unexpected Stats(): %+v", name, m.Stats())
 			}
+		}
 
-			if node, ok := child.nodes[0].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[0]=%T, unexpected type", child.nodes[0])
-			} else if got, exp := node.key, 2; got != exp {
-				t.Fatalf("key[0]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 3; got != exp {
-				t.Fatalf("value[0]=%v, expected %v", got, exp)
+		// A zero-value map chooses a default hasher on the first Set, as with
+		// NewMap(nil).
+		var m Map[string, int]
+		if v, ok := m.Set("a", 1).Get("a"); !ok || v != 1 {
+			t.Fatalf("Get()=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		for name, m := range map[string]*SortedMap[int, int]{"Zero": {}, "Nil": nil} {
+			if m.Len() != 0 {
+				t.Fatalf("%s: Len()=%d", name, m.Len())
+			} else if _, ok := m.Get(1); ok {
+				t.Fatalf("%s: expected Get() to fail", name)
+			} else if itr := m.Iterator(); !itr.Done() {
+				t.Fatalf("%s: expected iterator to be done", name)
+			} else if itr.Last(); !itr.Done() {
+				t.Fatalf("%s: expected Last() to leave iterator done", name)
+			} else if itr.Seek(1); !itr.Done() {
+				t.Fatalf("%s: expected Seek() to leave iterator done", name)
+			} else if m.Delete(1) != m {
+				t.Fatalf("%s: expected Delete() to return the original map", name)
 			}
-			if node, ok := child.nodes[1].(*mapValueNode[int, int]); !ok {
-				t.Fatalf("node[1]=%T, unexpected type", child.nodes[1])
-			} else if got, exp := node.key, 4; got != exp {
-				t.Fatalf("key[1]=%v, expected %v", got, exp)
-			} else if got, exp := node.value, 5; got != exp {
-				t.Fatalf("value[1]=%v, expected %v", got, exp)
+			m.RangeFunc(0, 10, func(k, v int) bool {
+				t.Fatalf("%s: unexpected pair in RangeFunc()", name)
+				return true
+			})
+			if left, right := m.Split(1); left.Len() != 0 || right.Len() != 0 {
+				t.Fatalf("%s: Split() lengths=%d,%d", name, left.Len(), right.Len())
+			} else if m.Comparer() != nil {
+				t.Fatalf("%s: expected nil Comparer()", name)
 			}
+		}
+	})
 
-			// Ensure both values can be read.
-			if v, ok := other.get(2, 0, h.Hash(2), h); !ok || v != 3 {
-				t.Fatalf("Get(2)=<%v,%v>", v, ok)
-			} else if v, ok := other.get(4, 0, h.Hash(4), h); !ok || v != 5 {
-				t.Fatalf("Get(4)=<%v,%v>", v, ok)
-			} else if v, ok := other.get(10, 0, h.Hash(10), h); ok {
-				t.Fatalf("Get(10)=<%v,%v>, expected no value", v, ok)
-			}
-		})
+	t.Run("List", func(t *testing.T) {
+		var l List[int]
+		if l.Len() != 0 {
+			t.Fatalf("Len()=%d", l.Len())
+		} else if _, ok := l.Head(); ok {
+			t.Fatal("expected Head() to fail")
+		} else if _, ok := l.Last(); ok {
+			t.Fatal("expected Last() to fail")
+		} else if itr := l.Iterator(); !itr.Done() {
+			t.Fatal("expected iterator to be done")
+		} else if l.IndexOf(1, func(a, b int) bool { return a == b }) != -1 {
+			t.Fatal("expected IndexOf() to fail")
+		}
+
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			l.Get(0)
+		}()
+		if r != `immutable.List.Get: index 0 out of bounds` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
 	})
 }
 
-func TestMap_Get(t *testing.T) {
+func TestMap_GetBatch(t *testing.T) {
+	m := NewMap[int, string](nil)
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, fmt.Sprint(i))
+	}
+
+	t.Run("AllPresent", func(t *testing.T) {
+		found, missing := m.GetBatch([]int{1, 50, 99})
+		if len(found) != 3 || found[1] != "1" || found[50] != "50" || found[99] != "99" {
+			t.Fatalf("found=%v", found)
+		} else if len(missing) != 0 {
+			t.Fatalf("missing=%v", missing)
+		}
+	})
+
+	t.Run("AllMissing", func(t *testing.T) {
+		found, missing := m.GetBatch([]int{-1, 100, 200})
+		if len(found) != 0 {
+			t.Fatalf("found=%v", found)
+		} else if got, exp := fmt.Sprint(missing), "[-1 100 200]"; got != exp {
+			t.Fatalf("missing=%s, expected %s", got, exp)
+		}
+	})
+
+	t.Run("Mixed", func(t *testing.T) {
+		found, missing := m.GetBatch([]int{5, 500, 5, 6, 500, -1, 6})
+		if len(found) != 2 || found[5] != "5" || found[6] != "6" {
+			t.Fatalf("found=%v", found)
+		} else if got, exp := fmt.Sprint(missing), "[500 -1]"; got != exp {
+			t.Fatalf("missing=%s, expected %s", got, exp)
+		}
+	})
+
 	t.Run("Empty", func(t *testing.T) {
-		m := NewMap[int, string](nil)
-		if v, ok := m.Get(100); ok {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+		found, missing := NewMap[int, string](nil).GetBatch([]int{1})
+		if len(found) != 0 || len(missing) != 1 {
+			t.Fatalf("found=%v, missing=%v", found, missing)
+		} else if found, missing := m.GetBatch(nil); len(found) != 0 || len(missing) != 0 {
+			t.Fatalf("found=%v, missing=%v", found, missing)
 		}
 	})
 }
 
-func TestMap_Set(t *testing.T) {
-	t.Run("Simple", func(t *testing.T) {
-		m := NewMap[int, string](nil)
-		itr := m.Iterator()
-		if !itr.Done() {
-			t.Fatal("MapIterator.Done()=true, expected false")
-		} else if k, v, ok := itr.Next(); ok {
-			t.Fatalf("MapIterator.Next()=<%v,%v>, expected nil", k, v)
+func TestMap_GetEntry(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		n       int
+		collide bool
+	}{
+		{"Small", 4, false},
+		{"Large", 1000, false},
+		{"Collision", 20, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := make([]*string, tt.n)
+			m := NewMap[*string, int](&stringPtrHasher{collide: tt.collide})
+			for i := range stored {
+				k := fmt.Sprint(i)
+				stored[i] = &k
+				m = m.Set(stored[i], i)
+			}
+
+			for i := range stored {
+				other := fmt.Sprint(i)
+				if k, v, ok := m.GetEntry(&other); !ok || v != i {
+					t.Fatalf("GetEntry(%q)=<%v,%v>", other, v, ok)
+				} else if k != stored[i] {
+					t.Fatalf("GetEntry(%q) returned a different key instance", other)
+				}
+			}
+			missing := "missing"
+			if k, v, ok := m.GetEntry(&missing); ok || k != nil || v != 0 {
+				t.Fatalf("GetEntry(missing)=<%v,%v,%v>", k, v, ok)
+			}
+		})
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		if k, v, ok := NewMap[string, int](nil).GetEntry("foo"); ok || k != "" || v != 0 {
+			t.Fatalf("GetEntry()=<%q,%v,%v>", k, v, ok)
 		}
 	})
+}
 
-	t.Run("Simple", func(t *testing.T) {
-		m := NewMap[int, string](nil)
-		m = m.Set(100, "foo")
-		if v, ok := m.Get(100); !ok || v != "foo" {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+// stringPtrHasher hashes string pointers by the value they point to so that
+// distinct instances with the same contents are equal.
+type stringPtrHasher struct {
+	collide bool
+}
+
+func (h *stringPtrHasher) Hash(key *string) uint32 {
+	if h.collide {
+		return 0
+	}
+	return hashString(*key)
+}
+
+func (h *stringPtrHasher) Equal(a, b *string) bool {
+	return *a == *b
+}
+
+func TestMap_GetOrElse(t *testing.T) {
+	m := NewMap[string, int](nil).Set("foo", 1).Set("zero", 0)
+
+	var calls int
+	fallback := func() int { calls++; return -1 }
+	if got, exp := m.GetOrElse("foo", fallback), 1; got != exp {
+		t.Fatalf("GetOrElse()=%d, exp %d", got, exp)
+	} else if got, exp := m.GetOrElse("zero", fallback), 0; got != exp {
+		t.Fatalf("GetOrElse()=%d, exp %d", got, exp)
+	} else if calls != 0 {
+		t.Fatalf("unexpected fallback calls: %d", calls)
+	}
+
+	if got, exp := m.GetOrElse("bar", fallback), -1; got != exp {
+		t.Fatalf("GetOrElse()=%d, exp %d", got, exp)
+	} else if calls != 1 {
+		t.Fatalf("unexpected fallback calls: %d", calls)
+	}
+}
+
+func TestMap_ComputeIfAbsent(t *testing.T) {
+	var calls int
+	f := func(key string) int { calls++; return len(key) }
+
+	m := NewMap[string, int](nil).Set("zero", 0)
+	v, other := m.ComputeIfAbsent("foo", f)
+	if v != 3 || calls != 1 {
+		t.Fatalf("ComputeIfAbsent()=%d, calls=%d", v, calls)
+	} else if got, ok := other.Get("foo"); !ok || got != 3 {
+		t.Fatalf("Get()=<%v,%v>", got, ok)
+	} else if _, ok := m.Get("foo"); ok {
+		t.Fatal("expected original map to be unchanged")
+	}
+
+	// Existing keys, including zero values, are returned without calling f.
+	if v, again := other.ComputeIfAbsent("foo", f); v != 3 || again != other || calls != 1 {
+		t.Fatalf("ComputeIfAbsent()=%d, calls=%d", v, calls)
+	} else if v, again := other.ComputeIfAbsent("zero", f); v != 0 || again != other || calls != 1 {
+		t.Fatalf("ComputeIfAbsent()=%d, calls=%d", v, calls)
+	}
+}
+
+func TestMap_Update(t *testing.T) {
+	t.Run("Counter", func(t *testing.T) {
+		incr := func(old int, existed bool) (int, bool) { return old + 1, true }
+
+		m := NewMap[string, int](nil)
+		for _, word := range strings.Fields("a b a c a b") {
+			m = m.Update(word, incr)
+		}
+		if got, exp := m.Len(), 3; got != exp {
+			t.Fatalf("Len()=%d, exp %d", got, exp)
+		}
+		for word, exp := range map[string]int{"a": 3, "b": 2, "c": 1} {
+			if got, ok := m.Get(word); !ok || got != exp {
+				t.Fatalf("Get(%q)=<%v,%v>, exp %d", word, got, ok, exp)
+			}
 		}
 	})
 
-	t.Run("VerySmall", func(t *testing.T) {
-		const n = 6
-		m := NewMap[int, int](nil)
-		for i := 0; i < n; i++ {
-			m = m.Set(i, i+1)
+	t.Run("ConditionalDelete", func(t *testing.T) {
+		decr := func(old int, existed bool) (int, bool) { return old - 1, old > 1 }
+
+		m := NewMap[string, int](nil).Set("a", 2).Set("b", 1)
+		m = m.Update("a", decr).Update("b", decr)
+		if got, ok := m.Get("a"); !ok || got != 1 {
+			t.Fatalf("Get(a)=<%v,%v>", got, ok)
+		} else if _, ok := m.Get("b"); ok {
+			t.Fatal("expected b to be deleted")
+		} else if got, exp := m.Len(), 1; got != exp {
+			t.Fatalf("Len()=%d, exp %d", got, exp)
 		}
-		for i := 0; i < n; i++ {
-			if v, ok := m.Get(i); !ok || v != i+1 {
-				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+	})
+
+	t.Run("MissingNoop", func(t *testing.T) {
+		m := NewMap[string, int](nil).Set("a", 1)
+		other := m.Update("b", func(old int, existed bool) (int, bool) {
+			if existed || old != 0 {
+				t.Fatalf("unexpected old value: <%v,%v>", old, existed)
 			}
+			return 0, false
+		})
+		if other != m {
+			t.Fatal("expected original map")
 		}
+	})
 
-		// NOTE: Array nodes store entries in insertion order.
-		itr := m.Iterator()
-		for i := 0; i < n; i++ {
-			if k, v, ok := itr.Next(); !ok || k != i || v != i+1 {
-				t.Fatalf("MapIterator.Next()=<%v,%v>, exp <%v,%v>", k, v, i, i+1)
+	// Compare against Get followed by Set or Delete across every node type,
+	// including hash collisions.
+	t.Run("Random", func(t *testing.T) {
+		rand := rand.New(rand.NewSource(0))
+		h := &mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value % 700)) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		exp, m := NewMap[int, int](h), NewMap[int, int](h)
+		for i := 0; i < 20000; i++ {
+			key, value, keep := rand.Intn(1000), rand.Int(), rand.Intn(3) != 0
+			if _, ok := exp.Get(key); keep {
+				exp = exp.Set(key, value)
+			} else if ok {
+				exp = exp.Delete(key)
+			}
+
+			var calls int
+			prev := m
+			m = m.Update(key, func(old int, existed bool) (int, bool) {
+				calls++
+				if v, ok := prev.Get(key); old != v || existed != ok {
+					t.Fatalf("Update(%d) old=<%v,%v>, expected <%v,%v>", key, old, existed, v, ok)
+				}
+				return value, keep
+			})
+			if calls != 1 {
+				t.Fatalf("f called %d times", calls)
+			} else if m.Len() != exp.Len() {
+				t.Fatalf("Len()=%d, expected %d", m.Len(), exp.Len())
 			}
 		}
-		if !itr.Done() {
-			t.Fatal("expected iterator done")
+		if !MapEqual(m, exp) {
+			t.Fatal("expected Update() to match Set() and Delete()")
 		}
 	})
 
-	t.Run("Small", func(t *testing.T) {
-		const n = 1000
+	// Updating a key only copies the path to its leaf.
+	t.Run("PathCopy", func(t *testing.T) {
 		m := NewMap[int, int](nil)
-		for i := 0; i < n; i++ {
-			m = m.Set(i, i+1)
+		for i := 0; i < 100000; i++ {
+			m = m.Set(i, i)
 		}
-		for i := 0; i < n; i++ {
-			if v, ok := m.Get(i); !ok || v != i+1 {
-				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+		nodes := make(map[mapNode[int, int]]struct{})
+		m.walk(func(n mapNode[int, int], depth int) { nodes[n] = struct{}{} })
+
+		var added int
+		other := m.Update(50000, func(old int, existed bool) (int, bool) { return -old, true })
+		other.walk(func(n mapNode[int, int], depth int) {
+			if _, ok := nodes[n]; !ok {
+				added++
 			}
+		})
+		if depth := m.Stats().Depth; added == 0 || added > depth {
+			t.Fatalf("Update() added %d nodes, expected at most %d", added, depth)
+		} else if v, _ := other.Get(50000); v != -50000 {
+			t.Fatalf("Get()=%d", v)
 		}
 	})
+}
 
-	t.Run("Large", func(t *testing.T) {
-		if testing.Short() {
-			t.Skip("skipping: short")
+func TestNewMapBuilderSized(t *testing.T) {
+	for _, n := range []int{1, 20, 10000} {
+		b := NewMapBuilderSized[int, int](nil, 10000)
+		for i := 0; i < n; i++ {
+			b.Set(i, i)
+		}
+		for i := 0; i < n; i += 2 {
+			b.Delete(i)
 		}
+		m := b.Map()
 
-		const n = 1000000
-		m := NewMap[int, int](nil)
-		for i := 0; i < n; i++ {
-			m = m.Set(i, i+1)
+		if got, exp := m.Len(), n/2; got != exp {
+			t.Fatalf("Len()=%d, exp %d", got, exp)
 		}
 		for i := 0; i < n; i++ {
-			if v, ok := m.Get(i); !ok || v != i+1 {
-				t.Fatalf("unexpected value for key=%v: <%v,%v>", i, v, ok)
+			if v, ok := m.Get(i); ok != (i%2 == 1) || (ok && v != i) {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+
+		var count int
+		itr := m.Iterator()
+		for !itr.Done() {
+			if k, v, _ := itr.Next(); k != v || k%2 != 1 {
+				t.Fatalf("unexpected pair: %d=%d", k, v)
 			}
+			count++
+		}
+		if count != n/2 {
+			t.Fatalf("iterated %d pairs, exp %d", count, n/2)
+		}
+	}
+}
+
+func TestBuilder_Finalized(t *testing.T) {
+	recoverString := func(fn func()) (r string) {
+		defer func() { r, _ = recover().(string) }()
+		fn()
+		return ""
+	}
+
+	t.Run("List", func(t *testing.T) {
+		b := NewListBuilder[int]()
+		b.Append(1)
+		l := b.List()
+		if r := recoverString(func() { b.Append(2) }); r != `immutable.ListBuilder: builder invalid after List() invocation` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if r := recoverString(func() { b.List() }); r != `immutable.ListBuilder.List(): duplicate call to fetch list` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if l.Len() != 1 || l.Get(0) != 1 {
+			t.Fatalf("unexpected list: %v", l)
 		}
 	})
 
-	t.Run("StringKeys", func(t *testing.T) {
-		m := NewMap[string, string](nil)
-		m = m.Set("foo", "bar")
-		m = m.Set("baz", "bat")
-		m = m.Set("", "EMPTY")
-		if v, ok := m.Get("foo"); !ok || v != "bar" {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
-		} else if v, ok := m.Get("baz"); !ok || v != "bat" {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
-		} else if v, ok := m.Get(""); !ok || v != "EMPTY" {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+	t.Run("Map", func(t *testing.T) {
+		b := NewMapBuilder[int, int](nil)
+		b.Set(1, 10)
+		m := b.Map()
+		if r := recoverString(func() { b.Set(2, 20) }); r != `immutable.MapBuilder: builder invalid after Map() invocation` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if r := recoverString(func() { b.Map() }); r != `immutable.MapBuilder.Map(): duplicate call to fetch map` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if v, ok := m.Get(1); !ok || v != 10 || m.Len() != 1 {
+			t.Fatalf("Get(1)=<%v,%v>", v, ok)
 		}
-		if v, ok := m.Get("no_such_key"); ok {
-			t.Fatalf("expected no value: <%v,%v>", v, ok)
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		b := NewSortedMapBuilder[int, int](nil)
+		b.Set(1, 10)
+		m := b.Map()
+		if r := recoverString(func() { b.Delete(1) }); r != `immutable.SortedMapBuilder: builder invalid after Map() invocation` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if v, ok := m.Get(1); !ok || v != 10 {
+			t.Fatalf("Get(1)=<%v,%v>", v, ok)
+		}
+	})
+}
+
+func TestMap_Rehash(t *testing.T) {
+	// Every key collides under the first hasher.
+	var hashes int
+	colliding := &mockHasher[int]{
+		hash:  func(v int) uint32 { return 0 },
+		equal: func(a, b int) bool { return a == b },
+	}
+	counting := &mockHasher[int]{
+		hash:  func(v int) uint32 { hashes++; return hashUint64(uint64(v)) },
+		equal: func(a, b int) bool { return a == b },
+	}
+
+	const n = 1000
+	m := NewMap[int, int](colliding)
+	for i := 0; i < n; i++ {
+		m = m.Set(i, i*2)
+	}
+	if got := m.Stats().Leaves; got != 1 {
+		t.Fatalf("Stats().Leaves=%d, expected a single collision node", got)
+	}
+
+	other := m.Rehash(counting)
+	if other.Len() != n {
+		t.Fatalf("Len()=%d, expected %d", other.Len(), n)
+	} else if other.hasher != Hasher[int](counting) {
+		t.Fatal("expected new hasher")
+	} else if got := other.Stats().Leaves; got < n/2 {
+		t.Fatalf("Stats().Leaves=%d, expected keys to be spread out", got)
+	}
+
+	hashes = 0
+	for i := 0; i < n; i++ {
+		if v, ok := other.Get(i); !ok || v != i*2 {
+			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+	if hashes != n {
+		t.Fatalf("new hasher called %d times, expected %d", hashes, n)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(i); !ok || v != i*2 {
+			t.Fatalf("original Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		if other := NewMap[int, int](colliding).Rehash(nil); other.Len() != 0 {
+			t.Fatalf("Len()=%d, expected 0", other.Len())
+		} else if v, ok := other.Set(1, 2).Get(1); !ok || v != 2 {
+			t.Fatalf("Get(1)=<%v,%v>", v, ok)
+		}
+	})
+}
+
+func TestMap_WithMutations(t *testing.T) {
+	m := NewMap[int, int](nil)
+	for i := 0; i < 500; i++ {
+		m = m.Set(i, i)
+	}
+
+	rand := rand.New(rand.NewSource(0))
+	type op struct {
+		del  bool
+		k, v int
+	}
+	ops := make([]op, 1000)
+	for i := range ops {
+		ops[i] = op{del: rand.Intn(3) == 0, k: rand.Intn(1000), v: rand.Int()}
+	}
+
+	exp := m
+	for _, op := range ops {
+		if op.del {
+			exp = exp.Delete(op.k)
+		} else {
+			exp = exp.Set(op.k, op.v)
 		}
-	})
+	}
 
-	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
-		m := NewTestMap()
-		for i := 0; i < 10000; i++ {
-			switch rand.Intn(2) {
-			case 1: // overwrite
-				m.Set(m.ExistingKey(rand), rand.Intn(10000))
-			default: // set new key
-				m.Set(m.NewKey(rand), rand.Intn(10000))
+	got := m.WithMutations(func(b *MapBuilder[int, int]) {
+		for _, op := range ops {
+			if op.del {
+				b.Delete(op.k)
+			} else {
+				b.Set(op.k, op.v)
 			}
 		}
-		if err := m.Validate(); err != nil {
-			t.Fatal(err)
-		}
 	})
+	if !MapEqual(got, exp) {
+		t.Fatal("expected WithMutations() to match individual edits")
+	} else if m.Len() != 500 {
+		t.Fatalf("original Len()=%d, exp 500", m.Len())
+	}
+	for i := 0; i < 500; i++ {
+		if v, ok := m.Get(i); !ok || v != i {
+			t.Fatalf("original Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
 }
 
-// Ensure map can support overwrites as it expands.
-func TestMap_Overwrite(t *testing.T) {
-	if testing.Short() {
-		t.Skip("short mode")
+func TestMap_Stats(t *testing.T) {
+	if stats := NewMap[int, int](nil).Stats(); stats != (MapStats{}) {
+		t.Fatalf("unexpected empty stats: %+v", stats)
+	} else if stats := NewMap[int, int](nil).Set(1, 1).Stats(); stats != (MapStats{Nodes: 1, Leaves: 1, Depth: 1}) {
+		t.Fatalf("unexpected array node stats: %+v", stats)
 	}
 
-	const n = 10000
 	m := NewMap[int, int](nil)
-	for i := 0; i < n; i++ {
-		// Set original value.
+	for i := 0; i < 100000; i++ {
 		m = m.Set(i, i)
-
-		// Overwrite every node.
-		for j := 0; j <= i; j++ {
-			m = m.Set(j, i*j)
-		}
+	}
+	stats := m.Stats()
+	if stats.Leaves < 1000 || stats.Nodes <= stats.Leaves || stats.Depth < 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
 	}
 
-	// Verify all key/value pairs in map.
-	for i := 0; i < n; i++ {
-		if v, ok := m.Get(i); !ok || v != i*(n-1) {
-			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+	// A single Set only copies the path to the updated leaf.
+	nodes := make(map[mapNode[int, int]]struct{})
+	m.walk(func(n mapNode[int, int], depth int) { nodes[n] = struct{}{} })
+
+	var added int
+	other := m.Set(50000, -1)
+	other.walk(func(n mapNode[int, int], depth int) {
+		if _, ok := nodes[n]; !ok {
+			added++
 		}
+	})
+	if added == 0 || added > stats.Depth {
+		t.Fatalf("Set() added %d nodes, expected at most %d", added, stats.Depth)
+	} else if got := other.Stats(); got != stats {
+		t.Fatalf("unexpected stats after Set(): %+v, exp %+v", got, stats)
 	}
 }
 
-func TestMap_Delete(t *testing.T) {
-	t.Run("Empty", func(t *testing.T) {
-		m := NewMap[string, int](nil)
-		other := m.Delete("foo")
-		if m != other {
-			t.Fatal("expected same map")
+func TestMap_Mutable(t *testing.T) {
+	m := NewMap[int, int](nil)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+
+	b := m.Mutable()
+	for i := 0; i < 1000; i += 2 {
+		b.Set(i, -i)
+	}
+	for i := 1; i < 1000; i += 4 {
+		b.Delete(i)
+	}
+	b.Set(1000, 1000)
+	other := b.Map()
+
+	if got, exp := other.Len(), 751; got != exp {
+		t.Fatalf("Len()=%d, exp %d", got, exp)
+	}
+	for i := 0; i < 1000; i++ {
+		v, ok := other.Get(i)
+		switch {
+		case i%2 == 0:
+			if !ok || v != -i {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		case i%4 == 1:
+			if ok {
+				t.Fatalf("Get(%d)=<%v,%v>, expected deleted", i, v, ok)
+			}
+		default:
+			if !ok || v != i {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
 		}
-	})
+	}
 
-	t.Run("Simple", func(t *testing.T) {
-		m := NewMap[int, string](nil)
-		m = m.Set(100, "foo")
-		if v, ok := m.Get(100); !ok || v != "foo" {
-			t.Fatalf("unexpected value: <%v,%v>", v, ok)
+	// Original map should be unchanged.
+	if got, exp := m.Len(), 1000; got != exp {
+		t.Fatalf("Len()=%d, exp %d", got, exp)
+	}
+	for i := 0; i < 1000; i++ {
+		if v, ok := m.Get(i); !ok || v != i {
+			t.Fatalf("original Get(%d)=<%v,%v>", i, v, ok)
 		}
-	})
+	}
 
-	t.Run("Small", func(t *testing.T) {
-		const n = 1000
-		m := NewMap[int, int](nil)
-		for i := 0; i < n; i++ {
-			m = m.Set(i, i+1)
+	// Only nodes on the edited paths are copied and each is copied once.
+	t.Run("CopyOnWrite", func(t *testing.T) {
+		nodes := make(map[mapNode[int, int]]struct{})
+		m.walk(func(n mapNode[int, int], depth int) { nodes[n] = struct{}{} })
+		added := func(m *Map[int, int]) (n int) {
+			m.walk(func(node mapNode[int, int], depth int) {
+				if _, ok := nodes[node]; !ok {
+					n++
+				}
+			})
+			return n
 		}
-		for i := range rand.New(rand.NewSource(0)).Perm(n) {
-			m = m.Delete(i)
+
+		b := m.Mutable()
+		for i := 0; i < 10; i++ {
+			b.Set(i*97, -i)
 		}
-		if m.Len() != 0 {
-			t.Fatalf("expected no elements, got %d", m.Len())
+		stats := m.Stats()
+		once := added(b.m)
+		if once == 0 || once > 10*stats.Depth {
+			t.Fatalf("Set() added %d nodes, expected at most %d", once, 10*stats.Depth)
 		}
-	})
 
-	t.Run("Large", func(t *testing.T) {
-		if testing.Short() {
-			t.Skip("skipping: short")
-		}
-		const n = 1000000
-		m := NewMap[int, int](nil)
-		for i := 0; i < n; i++ {
-			m = m.Set(i, i+1)
+		// Editing the same keys again updates the copies in-place.
+		for i := 0; i < 10; i++ {
+			b.Set(i*97, i)
 		}
-		for i := range rand.New(rand.NewSource(0)).Perm(n) {
-			m = m.Delete(i)
+		if got := added(b.m); got != once {
+			t.Fatalf("second Set() added %d nodes, expected %d", got, once)
 		}
-		if m.Len() != 0 {
-			t.Fatalf("expected no elements, got %d", m.Len())
+
+		// Freezing and reopening copies nodes again rather than sharing them.
+		frozen := b.Map()
+		b = frozen.Mutable()
+		b.Set(0, 100)
+		if v, _ := frozen.Get(0); v != 0 {
+			t.Fatalf("frozen Get(0)=%d, expected 0", v)
+		} else if v, _ := b.Get(0); v != 100 {
+			t.Fatalf("builder Get(0)=%d, expected 100", v)
 		}
 	})
+}
 
-	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
-		m := NewTestMap()
-		for i := 0; i < 10000; i++ {
-			switch rand.Intn(8) {
-			case 0: // overwrite
-				m.Set(m.ExistingKey(rand), rand.Intn(10000))
-			case 1: // delete existing key
-				m.Delete(m.ExistingKey(rand))
-			case 2: // delete non-existent key.
-				m.Delete(m.NewKey(rand))
-			default: // set new key
-				m.Set(m.NewKey(rand), rand.Intn(10000))
-			}
-		}
+func TestMap_Equal(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
 
-		// Delete all and verify they are gone.
-		keys := make([]int, len(m.keys))
-		copy(keys, m.keys)
+	// Build the same contents in a different order.
+	a, b := NewMap[int, int](nil), NewMap[int, int](nil)
+	for i := 0; i < 1000; i++ {
+		a = a.Set(i, i*2)
+		b = b.Set(999-i, (999-i)*2)
+	}
 
-		for _, key := range keys {
-			m.Delete(key)
-		}
-		if err := m.Validate(); err != nil {
-			t.Fatal(err)
-		}
-	})
+	if !a.Equal(a, eq) {
+		t.Fatal("expected map to equal itself")
+	} else if !a.Equal(b, eq) || !MapEqual(a, b) {
+		t.Fatal("expected maps to be equal")
+	} else if a.Equal(b.Set(500, -1), eq) || MapEqual(a, b.Set(500, -1)) {
+		t.Fatal("expected maps with different values to be unequal")
+	} else if a.Equal(b.Delete(500).Set(1000, 1000), eq) {
+		t.Fatal("expected maps with different keys to be unequal")
+	} else if a.Equal(b.Delete(500), eq) {
+		t.Fatal("expected maps with different lengths to be unequal")
+	} else if !NewMap[int, int](nil).Equal(NewMap[int, int](nil), eq) {
+		t.Fatal("expected empty maps to be equal")
+	}
+
+	// Values need not be comparable when a callback is given.
+	x := NewMap[string, []int](nil).Set("a", []int{1, 2})
+	y := NewMap[string, []int](nil).Set("a", []int{1, 2})
+	if !x.Equal(y, func(a, b []int) bool { return fmt.Sprint(a) == fmt.Sprint(b) }) {
+		t.Fatal("expected maps to be equal")
+	}
 }
 
-// Ensure map works even with hash conflicts.
-func TestMap_LimitedHash(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping: short")
+func TestMap_Clear(t *testing.T) {
+	h := &mockHasher[int]{
+		hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+		equal: func(a, b int) bool { return a == b },
+	}
+	m := NewMap[int, int](h).Set(1, 1).Set(2, 2)
+
+	other := m.Clear()
+	if got := other.Len(); got != 0 {
+		t.Fatalf("Len()=%d, exp 0", got)
+	} else if other.Hasher() != h {
+		t.Fatal("expected hasher to be retained")
+	} else if other.Clear() != other {
+		t.Fatal("expected empty map to be returned")
+	} else if got := m.Len(); got != 2 {
+		t.Fatalf("original Len()=%d, exp 2", got)
 	}
 
-	t.Run("Immutable", func(t *testing.T) {
-		h := mockHasher[int]{
-			hash:  func(value int) uint32 { return hashUint64(uint64(value)) % 0xFF },
-			equal: func(a, b int) bool { return a == b },
-		}
-		m := NewMap[int, int](&h)
+	if v, ok := other.Set(3, 3).Get(3); !ok || v != 3 {
+		t.Fatalf("Get(3)=<%v,%v>", v, ok)
+	}
+}
 
-		rand := rand.New(rand.NewSource(0))
-		keys := rand.Perm(100000)
-		for _, i := range keys {
-			m = m.Set(i, i) // initial set
-		}
-		for i := range keys {
-			m = m.Set(i, i*2) // overwrite
-		}
-		if m.Len() != len(keys) {
-			t.Fatalf("unexpected len: %d", m.Len())
-		}
+func TestMapIterator_Prev(t *testing.T) {
+	collide := &mockHasher[int]{
+		hash:  func(v int) uint32 { return uint32(v % 3) },
+		equal: func(a, b int) bool { return a == b },
+	}
 
-		// Verify all key/value pairs in map.
-		for i := 0; i < m.Len(); i++ {
-			if v, ok := m.Get(i); !ok || v != i*2 {
-				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+	// Sizes cover array, bitmap-indexed, hash array, and collision nodes.
+	for _, tt := range []struct {
+		name   string
+		hasher Hasher[int]
+		n      int
+	}{
+		{"Empty", nil, 0},
+		{"One", nil, 1},
+		{"Array", nil, 8},
+		{"Bitmap", nil, 12},
+		{"HashArray", nil, 10000},
+		{"Collision", collide, 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMap[int, int](tt.hasher)
+			for i := 0; i < tt.n; i++ {
+				m = m.Set(i, i*2)
 			}
-		}
 
-		// Verify iteration.
-		itr := m.Iterator()
-		for !itr.Done() {
-			if k, v, ok := itr.Next(); !ok || v != k*2 {
-				t.Fatalf("MapIterator.Next()=<%v,%v>, expected value %v", k, v, k*2)
+			var forward []int
+			for itr := m.Iterator(); !itr.Done(); {
+				k, _, _ := itr.Next()
+				forward = append(forward, k)
 			}
-		}
-
-		// Verify not found works.
-		if _, ok := m.Get(10000000); ok {
-			t.Fatal("expected no value")
-		}
 
-		// Verify delete non-existent key works.
-		if other := m.Delete(10000000 + 1); m != other {
-			t.Fatal("expected no change")
-		}
+			itr := m.Iterator()
+			itr.Last()
+			for i := len(forward) - 1; i >= 0; i-- {
+				k, v, ok := itr.Prev()
+				if !ok || k != forward[i] || v != k*2 {
+					t.Fatalf("Prev()=<%v,%v,%v>, expected key %d", k, v, ok, forward[i])
+				}
+			}
+			if !itr.Done() {
+				t.Fatal("expected iterator to be done")
+			} else if _, _, ok := itr.Prev(); ok {
+				t.Fatal("expected Prev() to fail")
+			}
+		})
+	}
 
-		// Remove all keys.
-		for _, key := range keys {
-			m = m.Delete(key)
+	t.Run("ChangeDirection", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		for i := 0; i < 100; i++ {
+			m = m.Set(i, i)
 		}
-		if m.Len() != 0 {
-			t.Fatalf("unexpected size: %d", m.Len())
+		itr := m.Iterator()
+		a, _, _ := itr.Next()
+		b, _, _ := itr.Next()
+		if k, _, _ := itr.Prev(); k == a || k == b {
+			t.Fatalf("Prev()=%d, expected the pair after %d", k, b)
+		} else if k, _, _ := itr.Prev(); k != b {
+			t.Fatalf("Prev()=%d, expected %d", k, b)
+		} else if k, _, _ := itr.Prev(); k != a {
+			t.Fatalf("Prev()=%d, expected %d", k, a)
 		}
 	})
+}
 
-	t.Run("Builder", func(t *testing.T) {
-		h := mockHasher[int]{
-			hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+func TestMapIterator_Seek(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		n    int
+		h    Hasher[int]
+	}{
+		{"Array", 5, nil},
+		{"Bitmap", 100, nil},
+		{"HashArray", 10000, nil},
+		{"Collision", 50, &mockHasher[int]{
+			hash:  func(value int) uint32 { return uint32(value % 4) },
 			equal: func(a, b int) bool { return a == b },
-		}
-		b := NewMapBuilder[int, int](&h)
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMap[int, int](tt.h)
+			for i := 0; i < tt.n; i++ {
+				m = m.Set(i, i*2)
+			}
 
-		rand := rand.New(rand.NewSource(0))
-		keys := rand.Perm(100000)
-		for _, i := range keys {
-			b.Set(i, i) // initial set
-		}
-		for i := range keys {
-			b.Set(i, i*2) // overwrite
-		}
-		if b.Len() != len(keys) {
-			t.Fatalf("unexpected len: %d", b.Len())
-		}
+			var all []int
+			for itr := m.Iterator(); !itr.Done(); {
+				k, _, _ := itr.Next()
+				all = append(all, k)
+			}
 
-		// Verify all key/value pairs in map.
-		for i := 0; i < b.Len(); i++ {
-			if v, ok := b.Get(i); !ok || v != i*2 {
-				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			// Read the first half, saving the next key as a checkpoint.
+			var got []int
+			itr := m.Iterator()
+			for len(got) < tt.n/2 {
+				k, _, _ := itr.Next()
+				got = append(got, k)
+			}
+			checkpoint, _, _ := itr.Next()
+
+			// Resume from the checkpoint with a new iterator.
+			itr = m.Iterator()
+			itr.Seek(checkpoint)
+			for !itr.Done() {
+				k, v, _ := itr.Next()
+				if v != k*2 {
+					t.Fatalf("unexpected pair: %d=%d", k, v)
+				}
+				got = append(got, k)
+			}
+
+			if len(got) != len(all) {
+				t.Fatalf("got %d keys, exp %d", len(got), len(all))
+			}
+			for i := range all {
+				if got[i] != all[i] {
+					t.Fatalf("key %d: got %d, exp %d", i, got[i], all[i])
+				}
+			}
+
+			for _, key := range all {
+				if itr.Seek(key); itr.Done() {
+					t.Fatalf("Seek(%d): unexpected done", key)
+				} else if k, _, _ := itr.Next(); k != key {
+					t.Fatalf("Seek(%d): Next()=%d", key, k)
+				}
 			}
+
+			itr.Seek(-1)
+			if !itr.Done() {
+				t.Fatal("expected iterator done after seeking to missing key")
+			}
+		})
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		itr := NewMap[int, int](nil).Iterator()
+		if itr.Seek(1); !itr.Done() {
+			t.Fatal("expected iterator done")
 		}
+	})
+}
 
-		// Verify iteration.
-		itr := b.Iterator()
+func TestMap_IteratorSorted(t *testing.T) {
+	less := func(a, b string) bool { return a < b }
+
+	// Build maps with the same contents in different orders.
+	a, b := NewMap[string, int](nil), NewMap[string, int](nil)
+	for i := 0; i < 1000; i++ {
+		a = a.Set(strconv.Itoa(i), i)
+		b = b.Set(strconv.Itoa(999-i), 999-i)
+	}
+
+	collect := func(itr *MapSortedIterator[string, int]) []string {
+		var keys []string
 		for !itr.Done() {
-			if k, v, ok := itr.Next(); !ok || v != k*2 {
-				t.Fatalf("MapIterator.Next()=<%v,%v>, expected value %v", k, v, k*2)
+			k, v, _ := itr.Next()
+			if k != strconv.Itoa(v) {
+				t.Fatalf("unexpected pair: %s=%d", k, v)
 			}
+			keys = append(keys, k)
 		}
+		return keys
+	}
+
+	keys := collect(a.IteratorSorted(less))
+	if len(keys) != 1000 {
+		t.Fatalf("unexpected key count: %d", len(keys))
+	} else if !sort.StringsAreSorted(keys) {
+		t.Fatal("expected keys to be sorted")
+	} else if got, exp := strings.Join(collect(b.IteratorSorted(less)), ","), strings.Join(keys, ","); got != exp {
+		t.Fatal("expected identical order for equal maps")
+	}
+
+	// Resetting the iterator repeats the same order.
+	itr := a.IteratorSorted(less)
+	collect(itr)
+	if k, _, ok := itr.Next(); ok {
+		t.Fatalf("unexpected key after done: %s", k)
+	}
+	itr.First()
+	if got, exp := strings.Join(collect(itr), ","), strings.Join(keys, ","); got != exp {
+		t.Fatal("expected identical order after First()")
+	}
+
+	if itr := NewMap[string, int](nil).IteratorSorted(less); !itr.Done() {
+		t.Fatal("expected empty iterator to be done")
+	}
+}
+
+func TestMap_Clone(t *testing.T) {
+	m := NewMap[int, int](nil).Set(1, 1)
+	if other := m.Clone(); other != m {
+		t.Fatal("expected Clone to return the same map")
+	}
+}
+
+// Ensure a shared map can be read and used to derive new maps from many
+// goroutines at once. Run with -race to detect data races in the read path.
+func TestMap_Concurrent(t *testing.T) {
+	const n = 1000
+	base := NewMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		base = base.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < n; i++ {
+				if v, ok := base.Get(i); !ok || v != i {
+					t.Errorf("Get(%d)=<%v,%v>", i, v, ok)
+					return
+				}
+			}
+			if got := base.Keys().Len(); got != n {
+				t.Errorf("Keys().Len()=%d, exp %d", got, n)
+				return
+			}
+
+			// Derive new maps from the shared base.
+			other := base.Set(g, -g).Delete(n-1-g).Set(n+g, g)
+			other = other.Filter(func(k, v int) bool { return k%2 == 0 || k == g })
+			other = other.MapValues(func(k, v int) int { return v * 2 })
+			if v, ok := other.Get(g); !ok || v != -g*2 {
+				t.Errorf("Get(%d)=<%v,%v>", g, v, ok)
+				return
+			}
 
-		// Verify not found works.
-		if _, ok := b.Get(10000000); ok {
-			t.Fatal("expected no value")
-		}
+			itr := base.Clone().Iterator()
+			count := 0
+			for !itr.Done() {
+				k, v, _ := itr.Next()
+				if k != v {
+					t.Errorf("unexpected pair: %d=%d", k, v)
+					return
+				}
+				count++
+			}
+			if count != n {
+				t.Errorf("iterated %d pairs, exp %d", count, n)
+			}
+		}(g)
+	}
+	wg.Wait()
 
-		// Remove all keys.
-		for _, key := range keys {
-			b.Delete(key)
-		}
-		if b.Len() != 0 {
-			t.Fatalf("unexpected size: %d", b.Len())
-		}
-	})
+	if got := base.Len(); got != n {
+		t.Fatalf("Len()=%d, exp %d", got, n)
+	}
 }
 
 // TMap represents a combined immutable and stdlib map.
@@ -1430,6 +3991,61 @@ func BenchmarkMapBuilder_Set(b *testing.B) {
 	}
 }
 
+func BenchmarkMapBuilder_Sized(b *testing.B) {
+	const n = 100000
+
+	b.Run("Sized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewMapBuilderSized[int, int](nil, n)
+			for j := 0; j < n; j++ {
+				builder.Set(j, j)
+			}
+		}
+	})
+
+	b.Run("Unsized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewMapBuilder[int, int](nil)
+			for j := 0; j < n; j++ {
+				builder.Set(j, j)
+			}
+		}
+	})
+}
+
+func BenchmarkMap_Mutable(b *testing.B) {
+	const n = 10000
+	base := NewMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		base = base.Set(i, i)
+	}
+
+	for _, edits := range []int{10, 100, n} {
+		b.Run(fmt.Sprintf("Mutable/%d", edits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				builder := base.Mutable()
+				for j := 0; j < edits; j++ {
+					builder.Set(j, -j)
+				}
+				builder.Map()
+			}
+		})
+
+		b.Run(fmt.Sprintf("Set/%d", edits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := base
+				for j := 0; j < edits; j++ {
+					m = m.Set(j, -j)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkMapBuilder_Delete(b *testing.B) {
 	const n = 10000000
 
@@ -1827,10 +4443,10 @@ func TestSortedMap_Set(t *testing.T) {
 		var r string
 		func() {
 			defer func() { r = recover().(string) }()
-			m := NewSortedMap[float64, string](nil)
-			m = m.Set(float64(100), "bar")
+			m := NewSortedMap[bool, string](nil)
+			m = m.Set(true, "bar")
 		}()
-		if r != `immutable.NewComparer: must set comparer for float64 type` {
+		if r != `immutable.NewComparer: must set comparer for bool type` {
 			t.Fatalf("unexpected panic: %q", r)
 		}
 	})
@@ -1956,107 +4572,547 @@ func TestSortedMap_Delete(t *testing.T) {
 				m.Set(m.NewKey(rand), rand.Intn(10000))
 			}
 		}
-		if err := m.Validate(); err != nil {
-			t.Fatal(err)
-		}
+		if err := m.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Delete all keys.
+		keys := make([]int, len(m.keys))
+		copy(keys, m.keys)
+		for _, k := range keys {
+			m.Delete(k)
+		}
+		if err := m.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSortedMap_Iterator(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		t.Run("First", func(t *testing.T) {
+			itr := NewSortedMap[int, int](nil).Iterator()
+			itr.First()
+			if k, v, ok := itr.Next(); ok {
+				t.Fatalf("SortedMapIterator.Next()=<%v,%v>, expected nil", k, v)
+			}
+		})
+
+		t.Run("Last", func(t *testing.T) {
+			itr := NewSortedMap[int, int](nil).Iterator()
+			itr.Last()
+			if k, v, ok := itr.Prev(); ok {
+				t.Fatalf("SortedMapIterator.Prev()=<%v,%v>, expected nil", k, v)
+			}
+		})
+
+		t.Run("Seek", func(t *testing.T) {
+			itr := NewSortedMap[string, int](nil).Iterator()
+			itr.Seek("foo")
+			if k, v, ok := itr.Next(); ok {
+				t.Fatalf("SortedMapIterator.Next()=<%v,%v>, expected nil", k, v)
+			}
+		})
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		const n = 100
+		m := NewSortedMap[string, int](nil)
+		for i := 0; i < n; i += 2 {
+			m = m.Set(fmt.Sprintf("%04d", i), i)
+		}
+
+		t.Run("Exact", func(t *testing.T) {
+			itr := m.Iterator()
+			for i := 0; i < n; i += 2 {
+				itr.Seek(fmt.Sprintf("%04d", i))
+				for j := i; j < n; j += 2 {
+					if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", j) {
+						t.Fatalf("%d/%d. SortedMapIterator.Next()=%v, expected key %04d", i, j, k, j)
+					}
+				}
+				if !itr.Done() {
+					t.Fatalf("SortedMapIterator.Done()=true, expected false")
+				}
+			}
+		})
+
+		t.Run("Miss", func(t *testing.T) {
+			itr := m.Iterator()
+			for i := 1; i < n-2; i += 2 {
+				itr.Seek(fmt.Sprintf("%04d", i))
+				for j := i + 1; j < n; j += 2 {
+					if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", j) {
+						t.Fatalf("%d/%d. SortedMapIterator.Next()=%v, expected key %04d", i, j, k, j)
+					}
+				}
+				if !itr.Done() {
+					t.Fatalf("SortedMapIterator.Done()=true, expected false")
+				}
+			}
+		})
+
+		t.Run("BeforeFirst", func(t *testing.T) {
+			itr := m.Iterator()
+			itr.Seek("")
+			for i := 0; i < n; i += 2 {
+				if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", i) {
+					t.Fatalf("%d. SortedMapIterator.Next()=%v, expected key %04d", i, k, i)
+				}
+			}
+			if !itr.Done() {
+				t.Fatalf("SortedMapIterator.Done()=true, expected false")
+			}
+		})
+		t.Run("AfterLast", func(t *testing.T) {
+			itr := m.Iterator()
+			itr.Seek("1000")
+			if k, _, ok := itr.Next(); ok {
+				t.Fatalf("0. SortedMapIterator.Next()=%v, expected nil key", k)
+			} else if !itr.Done() {
+				t.Fatalf("SortedMapIterator.Done()=true, expected false")
+			}
+		})
+	})
+}
+
+func TestNewSortedMapChecked(t *testing.T) {
+	// rockPaperScissors orders 1 < 2 < 3 < 1.
+	rockPaperScissors := func(a, b int) int {
+		if a == b {
+			return 0
+		} else if b == a%3+1 {
+			return -1
+		}
+		return 1
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		m := NewSortedMapChecked[int, int](nil)
+		for _, i := range rand.New(rand.NewSource(0)).Perm(1000) {
+			m = m.Set(i, i)
+		}
+		for i := 0; i < 1000; i += 2 {
+			m = m.Delete(i)
+		}
+		if got := m.Keys(); len(got) != 500 || got[0] != 1 || got[499] != 999 {
+			t.Fatalf("unexpected keys: len=%d", len(got))
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		compare func(a, b int) int
+		exp     string
+	}{
+		{"NotTransitive", rockPaperScissors, "immutable.SortedMap: invalid comparer: Compare(1, 2)=-1 and Compare(2, 3)=-1 but Compare(1, 3)=1"},
+		{"Asymmetric", func(a, b int) int { return -1 }, "immutable.SortedMap: invalid comparer: Compare(1, 2)=-1 but Compare(2, 1)=-1"},
+		{"OutOfRange", func(a, b int) int { return 2 * (a - b) }, "immutable.SortedMap: invalid comparer: Compare(1, 2)=-2, expected -1, 0, or 1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var r string
+			func() {
+				defer func() { r = recover().(string) }()
+				m := NewSortedMapChecked[int, int](&mockComparer[int]{compare: tt.compare})
+				m.Set(1, 1).Set(2, 2).Set(3, 3)
+			}()
+			if r != tt.exp {
+				t.Fatalf("unexpected panic: %q", r)
+			}
+		})
+	}
+
+	// Keys which only compare as equal are treated as the same key.
+	t.Run("Equivalent", func(t *testing.T) {
+		m := NewSortedMapChecked[string, int](CaseInsensitiveStringComparer{})
+		m = m.Set("a", 1).Set("A", 2).Set("b", 3)
+		if m.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", m.Len())
+		} else if v, ok := m.Get("a"); !ok || v != 2 {
+			t.Fatalf("Get(a)=<%v,%v>", v, ok)
+		}
+
+		zero := NewSortedMapChecked[int, int](&mockComparer[int]{compare: func(a, b int) int { return 0 }})
+		if zero = zero.Set(1, 1).Set(2, 2).Set(3, 3); zero.Len() != 1 {
+			t.Fatalf("Len()=%d, expected 1", zero.Len())
+		}
+	})
+
+	// A non-transitive comparer is caught deeper in the tree and by builders.
+	t.Run("NotTransitiveLarge", func(t *testing.T) {
+		compare := func(a, b int) int {
+			if a >= 600 && a <= 602 && b >= 600 && b <= 602 {
+				return rockPaperScissors(a-599, b-599)
+			}
+			return defaultCompare(a, b)
+		}
+		var r interface{}
+		func() {
+			defer func() { r = recover() }()
+			b := NewSortedMapBuilder[int, int](NewSortedMapChecked[int, int](&mockComparer[int]{compare: compare}).Comparer())
+			for i := 0; i <= 1000; i++ {
+				b.Set(i, i)
+			}
+		}()
+		if s, _ := r.(string); !strings.HasPrefix(s, "immutable.SortedMap: invalid comparer: ") {
+			t.Fatalf("unexpected panic: %v", r)
+		}
+	})
+}
+
+func TestSortedMap_Comparer(t *testing.T) {
+	c := &mockComparer[int]{compare: defaultCompare[int]}
+	if got := NewSortedMap[int, int](c).Set(1, 1).Comparer(); got != c {
+		t.Fatalf("Comparer()=%v, expected %v", got, c)
+	}
+
+	m := NewSortedMap[int, int](nil)
+	if got := m.Comparer(); got != nil {
+		t.Fatalf("Comparer()=%v, expected nil", got)
+	} else if got := m.Set(1, 1).Comparer(); got == nil {
+		t.Fatal("expected default comparer after Set")
+	}
+}
+
+func TestSortedMap_String(t *testing.T) {
+	if got, exp := NewSortedMap[string, int](nil).String(), "SortedMap{}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+
+	m := NewSortedMap[string, int](nil).Set("c", 3).Set("a", 1).Set("b", 2)
+	if got, exp := m.String(), "SortedMap{a:1, b:2, c:3}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+}
+
+func TestSortedMap_Clear(t *testing.T) {
+	reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+	m := NewSortedMap[int, int](reverse).Set(1, 1).Set(2, 2)
+
+	other := m.Clear()
+	if got := other.Len(); got != 0 {
+		t.Fatalf("Len()=%d, exp 0", got)
+	} else if other.Comparer() != reverse {
+		t.Fatal("expected comparer to be retained")
+	} else if other.Clear() != other {
+		t.Fatal("expected empty map to be returned")
+	}
+
+	// Reverse ordering should still apply after clearing.
+	other = other.Set(1, 1).Set(3, 3).Set(2, 2)
+	if got, exp := other.String(), "SortedMap{3:3, 2:2, 1:1}"; got != exp {
+		t.Fatalf("String()=%q, exp %q", got, exp)
+	}
+}
+
+func TestSortedMap_RangeFunc(t *testing.T) {
+	m := NewSortedMap[int, int](nil)
+	for i := 0; i < 1000; i += 10 {
+		m = m.Set(i, i*2)
+	}
+
+	collect := func(lo, hi int) []int {
+		var keys []int
+		m.RangeFunc(lo, hi, func(k, v int) bool {
+			if v != k*2 {
+				t.Fatalf("unexpected value for key %d: %d", k, v)
+			}
+			keys = append(keys, k)
+			return true
+		})
+		return keys
+	}
+
+	t.Run("Exists", func(t *testing.T) {
+		if keys := collect(100, 150); fmt.Sprint(keys) != "[100 110 120 130 140]" {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("NotExists", func(t *testing.T) {
+		if keys := collect(95, 131); fmt.Sprint(keys) != "[100 110 120 130]" {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+		if keys := collect(-100, 15); fmt.Sprint(keys) != "[0 10]" {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+		if keys := collect(975, 5000); fmt.Sprint(keys) != "[980 990]" {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("EmptyRange", func(t *testing.T) {
+		if keys := collect(101, 109); len(keys) != 0 {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+		if keys := collect(100, 100); len(keys) != 0 {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+		if keys := collect(200, 100); len(keys) != 0 {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+		if keys := collect(2000, 3000); len(keys) != 0 {
+			t.Fatalf("unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		var n int
+		m.RangeFunc(0, 1000, func(k, v int) bool {
+			n++
+			return n < 5
+		})
+		if n != 5 {
+			t.Fatalf("visited %d pairs, expected 5", n)
+		}
+	})
+
+	t.Run("EmptyMap", func(t *testing.T) {
+		NewSortedMap[int, int](nil).RangeFunc(0, 10, func(k, v int) bool {
+			t.Fatal("unexpected callback")
+			return true
+		})
+	})
+}
+
+func TestSortedMap_FloorCeiling(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewSortedMap[int, string](nil)
+		if k, v, ok := m.Floor(1); ok {
+			t.Fatalf("Floor()=<%v,%v,%v>", k, v, ok)
+		} else if k, v, ok := m.Ceiling(1); ok {
+			t.Fatalf("Ceiling()=<%v,%v,%v>", k, v, ok)
+		}
+	})
+
+	// Use enough keys to span multiple leaf nodes.
+	m := NewSortedMap[int, string](nil)
+	for i := 10; i <= 10000; i += 10 {
+		m = m.Set(i, strconv.Itoa(i))
+	}
+
+	for _, tt := range []struct {
+		key             int
+		floor, ceiling  int
+		floorOK, ceilOK bool
+	}{
+		{key: 5, ceiling: 10, ceilOK: true},
+		{key: 10, floor: 10, ceiling: 10, floorOK: true, ceilOK: true},
+		{key: 15, floor: 10, ceiling: 20, floorOK: true, ceilOK: true},
+		{key: 5005, floor: 5000, ceiling: 5010, floorOK: true, ceilOK: true},
+		{key: 10000, floor: 10000, ceiling: 10000, floorOK: true, ceilOK: true},
+		{key: 10005, floor: 10000, floorOK: true},
+	} {
+		if k, v, ok := m.Floor(tt.key); ok != tt.floorOK || k != tt.floor || (ok && v != strconv.Itoa(k)) {
+			t.Fatalf("Floor(%d)=<%v,%v,%v>, expected <%v,%v>", tt.key, k, v, ok, tt.floor, tt.floorOK)
+		}
+		if k, v, ok := m.Ceiling(tt.key); ok != tt.ceilOK || k != tt.ceiling || (ok && v != strconv.Itoa(k)) {
+			t.Fatalf("Ceiling(%d)=<%v,%v,%v>, expected <%v,%v>", tt.key, k, v, ok, tt.ceiling, tt.ceilOK)
+		}
+	}
+
+	// Check every target between the first and last keys so that lookups
+	// cross each leaf boundary.
+	for key := 10; key <= 10000; key++ {
+		floor, ceiling := key-key%10, key+(10-key%10)%10
+		if k, _, ok := m.Floor(key); !ok || k != floor {
+			t.Fatalf("Floor(%d)=<%v,%v>, expected %d", key, k, ok, floor)
+		} else if k, _, ok := m.Ceiling(key); !ok || k != ceiling {
+			t.Fatalf("Ceiling(%d)=<%v,%v>, expected %d", key, k, ok, ceiling)
+		}
+	}
+}
+
+func TestSortedMap_Entries(t *testing.T) {
+	m := NewSortedMap[int, string](nil)
+	for _, i := range rand.New(rand.NewSource(0)).Perm(1000) {
+		m = m.Set(i, fmt.Sprint(i))
+	}
+
+	entries := m.Entries()
+	if got, exp := entries.Len(), m.Len(); got != exp {
+		t.Fatalf("Entries().Len()=%d, exp %d", got, exp)
+	}
+
+	b := NewSortedMapBuilder[int, string](nil)
+	itr := entries.Iterator()
+	for !itr.Done() {
+		i, e := itr.Next()
+		if e.Key != i || e.Value != fmt.Sprint(i) {
+			t.Fatalf("Entries()[%d]=%v", i, e)
+		}
+		b.Set(e.Key, e.Value)
+	}
+	if other := b.Map(); other.Len() != m.Len() {
+		t.Fatalf("rebuilt Len()=%d, exp %d", other.Len(), m.Len())
+	}
+}
+
+func TestSortedMap_KeysValues(t *testing.T) {
+	reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+	m := NewSortedMap[int, string](reverse)
+	for _, i := range rand.New(rand.NewSource(0)).Perm(1000) {
+		m = m.Set(i, fmt.Sprint(i))
+	}
+
+	keys, values := m.Keys(), m.Values()
+	if len(keys) != 1000 || len(values) != 1000 {
+		t.Fatalf("len(Keys())=%d, len(Values())=%d, exp 1000", len(keys), len(values))
+	}
+	for i := range keys {
+		if exp := 999 - i; keys[i] != exp {
+			t.Fatalf("Keys()[%d]=%d, exp %d", i, keys[i], exp)
+		} else if values[i] != fmt.Sprint(exp) {
+			t.Fatalf("Values()[%d]=%q, exp %q", i, values[i], fmt.Sprint(exp))
+		}
+	}
+
+	empty := NewSortedMap[int, string](nil)
+	if keys, values := empty.Keys(), empty.Values(); keys == nil || len(keys) != 0 || values == nil || len(values) != 0 {
+		t.Fatalf("unexpected empty slices: %#v, %#v", keys, values)
+	}
+}
+
+func TestSortedMap_Split(t *testing.T) {
+	const n = 1000
+	m := NewSortedMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		m = m.Set(i*2, i)
+	}
+
+	for _, key := range []int{-1, 0, 1, 2, 500, 999, 1000, 1001, 1500, 1998, 1999, 5000} {
+		left, right := m.Split(key)
+		if got := left.Len() + right.Len(); got != n {
+			t.Fatalf("Split(%d): total Len()=%d, exp %d", key, got, n)
+		}
+
+		exp := 0
+		for _, half := range []*SortedMap[int, int]{left, right} {
+			itr := half.Iterator()
+			for !itr.Done() {
+				k, v, _ := itr.Next()
+				if k != exp*2 || v != exp {
+					t.Fatalf("Split(%d): unexpected pair %d=%d, exp %d=%d", key, k, v, exp*2, exp)
+				} else if (half == left) != (k < key) {
+					t.Fatalf("Split(%d): key %d in wrong half", key, k)
+				}
+				exp++
+			}
+		}
+	}
 
-		// Delete all keys.
-		keys := make([]int, len(m.keys))
-		copy(keys, m.keys)
-		for _, k := range keys {
-			m.Delete(k)
+	// Original map is unchanged.
+	if m.Len() != n {
+		t.Fatalf("Len()=%d, exp %d", m.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(i * 2); !ok || v != i {
+			t.Fatalf("Get(%d)=<%v,%v>", i*2, v, ok)
 		}
-		if err := m.Validate(); err != nil {
-			t.Fatal(err)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		left, right := NewSortedMap[int, int](nil).Split(10)
+		if left.Len() != 0 || right.Len() != 0 {
+			t.Fatalf("unexpected lengths: %d, %d", left.Len(), right.Len())
 		}
 	})
 }
 
-func TestSortedMap_Iterator(t *testing.T) {
-	t.Run("Empty", func(t *testing.T) {
-		t.Run("First", func(t *testing.T) {
-			itr := NewSortedMap[int, int](nil).Iterator()
-			itr.First()
-			if k, v, ok := itr.Next(); ok {
-				t.Fatalf("SortedMapIterator.Next()=<%v,%v>, expected nil", k, v)
-			}
-		})
+func TestSortedMap_Scan(t *testing.T) {
+	m := NewSortedMap[int, int](nil)
+	for i := 10; i <= 1000; i += 10 {
+		m = m.Set(i, i*2)
+	}
 
-		t.Run("Last", func(t *testing.T) {
-			itr := NewSortedMap[int, int](nil).Iterator()
-			itr.Last()
-			if k, v, ok := itr.Prev(); ok {
-				t.Fatalf("SortedMapIterator.Prev()=<%v,%v>, expected nil", k, v)
+	collect := func(scan func(from int, f func(k, v int) bool), from, limit int) []int {
+		var keys []int
+		scan(from, func(k, v int) bool {
+			if v != k*2 {
+				t.Fatalf("unexpected value for %d: %d", k, v)
 			}
+			keys = append(keys, k)
+			return len(keys) < limit
 		})
+		return keys
+	}
 
-		t.Run("Seek", func(t *testing.T) {
-			itr := NewSortedMap[string, int](nil).Iterator()
-			itr.Seek("foo")
-			if k, v, ok := itr.Next(); ok {
-				t.Fatalf("SortedMapIterator.Next()=<%v,%v>, expected nil", k, v)
+	t.Run("Forward", func(t *testing.T) {
+		for _, tt := range []struct {
+			from, limit int
+			exp         string
+		}{
+			{from: 500, limit: 3, exp: "[500 510 520]"},
+			{from: 505, limit: 3, exp: "[510 520 530]"},
+			{from: 0, limit: 2, exp: "[10 20]"},
+			{from: 990, limit: 100, exp: "[990 1000]"},
+			{from: 1005, limit: 100, exp: "[]"},
+		} {
+			if got := fmt.Sprint(collect(m.Scan, tt.from, tt.limit)); got != tt.exp {
+				t.Fatalf("Scan(%d)=%s, expected %s", tt.from, got, tt.exp)
 			}
-		})
+		}
+		if got := len(collect(m.Scan, 0, 1000)); got != 100 {
+			t.Fatalf("unexpected scan count: %d", got)
+		}
 	})
 
-	t.Run("Seek", func(t *testing.T) {
-		const n = 100
-		m := NewSortedMap[string, int](nil)
-		for i := 0; i < n; i += 2 {
-			m = m.Set(fmt.Sprintf("%04d", i), i)
+	t.Run("Reverse", func(t *testing.T) {
+		for _, tt := range []struct {
+			from, limit int
+			exp         string
+		}{
+			{from: 500, limit: 3, exp: "[500 490 480]"},
+			{from: 505, limit: 3, exp: "[500 490 480]"},
+			{from: 2000, limit: 2, exp: "[1000 990]"},
+			{from: 20, limit: 100, exp: "[20 10]"},
+			{from: 5, limit: 100, exp: "[]"},
+		} {
+			if got := fmt.Sprint(collect(m.ScanReverse, tt.from, tt.limit)); got != tt.exp {
+				t.Fatalf("ScanReverse(%d)=%s, expected %s", tt.from, got, tt.exp)
+			}
+		}
+		if got := len(collect(m.ScanReverse, 1000, 1000)); got != 100 {
+			t.Fatalf("unexpected scan count: %d", got)
 		}
+	})
 
-		t.Run("Exact", func(t *testing.T) {
-			itr := m.Iterator()
-			for i := 0; i < n; i += 2 {
-				itr.Seek(fmt.Sprintf("%04d", i))
-				for j := i; j < n; j += 2 {
-					if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", j) {
-						t.Fatalf("%d/%d. SortedMapIterator.Next()=%v, expected key %04d", i, j, k, j)
-					}
-				}
-				if !itr.Done() {
-					t.Fatalf("SortedMapIterator.Done()=true, expected false")
-				}
-			}
-		})
+	t.Run("Empty", func(t *testing.T) {
+		m := NewSortedMap[int, int](nil)
+		m.Scan(0, func(k, v int) bool { t.Fatal("unexpected call"); return true })
+		m.ScanReverse(0, func(k, v int) bool { t.Fatal("unexpected call"); return true })
+	})
+}
 
-		t.Run("Miss", func(t *testing.T) {
-			itr := m.Iterator()
-			for i := 1; i < n-2; i += 2 {
-				itr.Seek(fmt.Sprintf("%04d", i))
-				for j := i + 1; j < n; j += 2 {
-					if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", j) {
-						t.Fatalf("%d/%d. SortedMapIterator.Next()=%v, expected key %04d", i, j, k, j)
-					}
-				}
-				if !itr.Done() {
-					t.Fatalf("SortedMapIterator.Done()=true, expected false")
-				}
-			}
-		})
+func TestSortedMap_MinMaxKey(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		m := NewSortedMap[int, int](nil)
+		if k, ok := m.MinKey(); ok {
+			t.Fatalf("MinKey()=<%v,%v>, expected false", k, ok)
+		} else if k, ok := m.MaxKey(); ok {
+			t.Fatalf("MaxKey()=<%v,%v>, expected false", k, ok)
+		}
+	})
 
-		t.Run("BeforeFirst", func(t *testing.T) {
-			itr := m.Iterator()
-			itr.Seek("")
-			for i := 0; i < n; i += 2 {
-				if k, _, ok := itr.Next(); !ok || k != fmt.Sprintf("%04d", i) {
-					t.Fatalf("%d. SortedMapIterator.Next()=%v, expected key %04d", i, k, i)
-				}
-			}
-			if !itr.Done() {
-				t.Fatalf("SortedMapIterator.Done()=true, expected false")
-			}
-		})
-		t.Run("AfterLast", func(t *testing.T) {
-			itr := m.Iterator()
-			itr.Seek("1000")
-			if k, _, ok := itr.Next(); ok {
-				t.Fatalf("0. SortedMapIterator.Next()=%v, expected nil key", k)
-			} else if !itr.Done() {
-				t.Fatalf("SortedMapIterator.Done()=true, expected false")
-			}
-		})
+	t.Run("Single", func(t *testing.T) {
+		m := NewSortedMap[int, int](nil).Set(5, 50)
+		if k, ok := m.MinKey(); !ok || k != 5 {
+			t.Fatalf("MinKey()=<%v,%v>", k, ok)
+		} else if k, ok := m.MaxKey(); !ok || k != 5 {
+			t.Fatalf("MaxKey()=<%v,%v>", k, ok)
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		m := NewSortedMap[int, int](nil)
+		for i := 0; i < 10000; i++ {
+			m = m.Set((i*7919)%10000, i)
+		}
+		if k, ok := m.MinKey(); !ok || k != 0 {
+			t.Fatalf("MinKey()=<%v,%v>", k, ok)
+		} else if k, ok := m.MaxKey(); !ok || k != 9999 {
+			t.Fatalf("MaxKey()=<%v,%v>", k, ok)
+		}
 	})
 }
 
@@ -2074,6 +5130,11 @@ func TestNewHasher(t *testing.T) {
 		t.Run("uint32", func(t *testing.T) { testNewHasher(t, uint32(100)) })
 		t.Run("uint64", func(t *testing.T) { testNewHasher(t, uint64(100)) })
 
+		t.Run("float32", func(t *testing.T) { testNewHasher(t, float32(1.5)) })
+		t.Run("float64", func(t *testing.T) { testNewHasher(t, float64(1.5)) })
+
+		t.Run("bool", func(t *testing.T) { testNewHasherComparable(t, true, false) })
+
 		t.Run("string", func(t *testing.T) { testNewHasher(t, "foo") })
 		//t.Run("byteSlice", func(t *testing.T) { testNewHasher(t, []byte("foo")) })
 	})
@@ -2087,9 +5148,100 @@ func TestNewHasher(t *testing.T) {
 
 		type String string
 		t.Run("string", func(t *testing.T) { testNewHasher(t, String("foo")) })
+
+		type Float float64
+		t.Run("float", func(t *testing.T) { testNewHasher(t, Float(1.5)) })
+
+		type Bool bool
+		t.Run("bool", func(t *testing.T) { testNewHasherComparable(t, Bool(true), Bool(false)) })
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		m := NewMap[float64, int](nil)
+		for i := 0; i < 1000; i++ {
+			m = m.Set(float64(i)+0.5, i)
+		}
+		for i := 0; i < 1000; i++ {
+			if v, ok := m.Get(float64(i) + 0.5); !ok || v != i {
+				t.Fatalf("Get(%v)=<%v,%v>", float64(i)+0.5, v, ok)
+			}
+		}
+
+		b := NewMap[bool, string](nil).Set(true, "yes").Set(false, "no")
+		if v, ok := b.Get(false); !ok || v != "no" {
+			t.Fatalf("Get(false)=<%v,%v>", v, ok)
+		} else if b.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", b.Len())
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		nan, other := math.NaN(), math.Float64frombits(0x7ff8000000000001)
+		h64 := NewHasher(nan)
+		if !h64.Equal(nan, other) || h64.Hash(nan) != h64.Hash(other) {
+			t.Fatal("expected NaN values to be equal with the same hash")
+		} else if h64.Equal(nan, 1) || h64.Equal(1, nan) {
+			t.Fatal("expected NaN to be unequal to a number")
+		}
+		h32 := NewHasher(float32(nan))
+		if !h32.Equal(float32(nan), float32(other)) || h32.Hash(float32(nan)) != h32.Hash(float32(other)) {
+			t.Fatal("expected float32 NaN values to be equal with the same hash")
+		}
+		type Float float64
+		hr := NewHasher(Float(nan))
+		if !hr.Equal(Float(nan), Float(other)) || hr.Hash(Float(nan)) != hr.Hash(Float(other)) {
+			t.Fatal("expected reflected NaN values to be equal with the same hash")
+		} else if hr.Equal(Float(nan), 1) {
+			t.Fatal("expected reflected NaN to be unequal to a number")
+		}
+
+		if got := NewSet[float64](nil).Set(nan).Set(other).Len(); got != 1 {
+			t.Fatalf("Len()=%d, expected a single NaN element", got)
+		}
+		m := NewMap[float64, string](nil).Set(1, "one").Set(nan, "nan").Set(nan, "NaN")
+		if m.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", m.Len())
+		} else if v, ok := m.Get(other); !ok || v != "NaN" {
+			t.Fatalf("Get(NaN)=<%v,%v>", v, ok)
+		} else if m = m.Delete(nan); m.Len() != 1 {
+			t.Fatalf("Len()=%d after Delete(NaN), expected 1", m.Len())
+		}
+	})
+
+	t.Run("Explicit", func(t *testing.T) {
+		h := &mockHasher[float64]{
+			hash:  func(value float64) uint32 { return 0 },
+			equal: func(a, b float64) bool { return a == b },
+		}
+		m := NewMap[float64, int](h).Set(1, 1).Set(2, 2)
+		if m.hasher != h {
+			t.Fatal("expected explicit hasher to be used")
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		type key struct{ a, b int }
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "must set hasher") {
+				t.Fatalf("unexpected panic: %v", r)
+			}
+		}()
+		NewMap[key, int](nil).Set(key{1, 2}, 3)
 	})
 }
 
+func testNewHasherComparable[V comparable](t *testing.T, v, other V) {
+	t.Helper()
+	h := NewHasher(v)
+	if h.Hash(v) == h.Hash(other) {
+		t.Fatal("expected distinct hashes")
+	} else if !h.Equal(v, v) {
+		t.Fatal("expected hash equality")
+	} else if h.Equal(v, other) {
+		t.Fatal("expected hash inequality")
+	}
+}
+
 func testNewHasher[V constraints.Ordered](t *testing.T, v V) {
 	t.Helper()
 	h := NewHasher(v)
@@ -2113,6 +5265,11 @@ func TestNewComparer(t *testing.T) {
 		t.Run("uint32", func(t *testing.T) { testNewComparer(t, uint32(100), uint32(101)) })
 		t.Run("uint64", func(t *testing.T) { testNewComparer(t, uint64(100), uint64(101)) })
 
+		t.Run("float32", func(t *testing.T) { testNewComparer(t, float32(1.5), float32(2.5)) })
+		t.Run("float64", func(t *testing.T) { testNewComparer(t, float64(1.5), float64(2.5)) })
+		t.Run("float32NaN", func(t *testing.T) { testNewComparer(t, float32(math.NaN()), float32(-1.5)) })
+		t.Run("float64NaN", func(t *testing.T) { testNewComparer(t, math.NaN(), math.Inf(-1)) })
+
 		t.Run("string", func(t *testing.T) { testNewComparer(t, "bar", "foo") })
 		//t.Run("byteSlice", func(t *testing.T) { testNewComparer(t, []byte("bar"), []byte("foo")) })
 	})
@@ -2126,6 +5283,73 @@ func TestNewComparer(t *testing.T) {
 
 		type String string
 		t.Run("string", func(t *testing.T) { testNewComparer(t, String("bar"), String("foo")) })
+
+		type Float float64
+		t.Run("float", func(t *testing.T) { testNewComparer(t, Float(1.5), Float(2.5)) })
+		t.Run("floatNaN", func(t *testing.T) { testNewComparer(t, Float(math.NaN()), Float(-1.5)) })
+	})
+}
+
+func TestDefaultComparer(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		c := DefaultComparer[int]()
+		if c.Compare(1, 2) != -1 || c.Compare(2, 2) != 0 || c.Compare(3, 2) != 1 {
+			t.Fatal("unexpected comparison")
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		c := DefaultComparer[float64]()
+		nan := math.NaN()
+		if c.Compare(nan, nan) != 0 || c.Compare(nan, math.Inf(-1)) != -1 || c.Compare(0, nan) != 1 {
+			t.Fatal("unexpected comparison")
+		}
+
+		for name, comparer := range map[string]Comparer[float64]{"Default": c, "Nil": nil} {
+			m := NewSortedMap[float64, string](comparer)
+			m = m.Set(1, "one").Set(2, "two").Set(nan, "nan").Set(nan, "NaN")
+			if m.Len() != 3 {
+				t.Fatalf("%s: Len()=%d, expected 3", name, m.Len())
+			} else if got := fmt.Sprint(m.Keys()); got != "[NaN 1 2]" {
+				t.Fatalf("%s: Keys()=%s", name, got)
+			} else if v, ok := m.Get(1); !ok || v != "one" {
+				t.Fatalf("%s: Get(1)=<%v,%v>", name, v, ok)
+			} else if v, ok := m.Get(nan); !ok || v != "NaN" {
+				t.Fatalf("%s: Get(NaN)=<%v,%v>", name, v, ok)
+			}
+		}
+
+		// A checked comparer accepts NaN keys comparing equal.
+		m := NewSortedMapChecked[float64, int](nil).Set(nan, 1).Set(nan, 2).Set(1, 3)
+		if m.Len() != 2 {
+			t.Fatalf("checked: Len()=%d, expected 2", m.Len())
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		m := NewSortedMap[string, int](DefaultComparer[string]())
+		for _, k := range []string{"c", "a", "b"} {
+			m = m.Set(k, 0)
+		}
+		itr := m.Iterator()
+		for _, exp := range []string{"a", "b", "c"} {
+			if k, _, ok := itr.Next(); !ok || k != exp {
+				t.Fatalf("Next()=<%v,%v>, expected %v", k, ok, exp)
+			}
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		m := NewSortedMap[float64, int](nil)
+		for _, k := range []float64{2.5, -1, 0.25} {
+			m = m.Set(k, 0)
+		}
+		itr := m.Iterator()
+		for _, exp := range []float64{-1, 0.25, 2.5} {
+			if k, _, ok := itr.Next(); !ok || k != exp {
+				t.Fatalf("Next()=<%v,%v>, expected %v", k, ok, exp)
+			}
+		}
 	})
 }
 
@@ -2141,6 +5365,257 @@ func testNewComparer[T constraints.Ordered](t *testing.T, x, y T) {
 	}
 }
 
+func TestUint64Hasher(t *testing.T) {
+	const n = 100000
+	m := NewMap[uint64, int](Uint64Hasher{})
+	for i := 0; i < n; i++ {
+		m = m.Set(uint64(i)<<32|uint64(i), i) // vary both the high & low words
+	}
+	if m.Len() != n {
+		t.Fatalf("Len()=%d, expected %d", m.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(uint64(i)<<32 | uint64(i)); !ok || v != i {
+			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+}
+
+func TestInt64Hasher(t *testing.T) {
+	const n = 100000
+	m := NewMap[int64, int](Int64Hasher{})
+	for i := -n / 2; i < n/2; i++ {
+		m = m.Set(int64(i)*1000003, i)
+	}
+	if m.Len() != n {
+		t.Fatalf("Len()=%d, expected %d", m.Len(), n)
+	}
+	for i := -n / 2; i < n/2; i++ {
+		if v, ok := m.Get(int64(i) * 1000003); !ok || v != i {
+			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+}
+
+func TestNewSeededHasher(t *testing.T) {
+	t.Run("Distribution", func(t *testing.T) {
+		h1, h2 := NewSeededHasher[int](nil, 1), NewSeededHasher[int](nil, 2)
+
+		// Count keys assigned to a different root slot under each seed.
+		var moved int
+		for i := 0; i < 1000; i++ {
+			if h1.Hash(i)&mapNodeMask != h2.Hash(i)&mapNodeMask {
+				moved++
+			}
+		}
+		if moved < 500 {
+			t.Fatalf("only %d of 1000 keys changed slots between seeds", moved)
+		}
+	})
+
+	t.Run("Collisions", func(t *testing.T) {
+		// These strings collide under the built-in string hasher.
+		def := NewHasher("")
+		if def.Hash("Aa") != def.Hash("BB") {
+			t.Fatal("expected default hashes to collide")
+		}
+		h := NewSeededHasher[string](nil, 12345)
+		if h.Hash("Aa") == h.Hash("BB") {
+			t.Fatal("expected seeded hashes to differ")
+		} else if h.Hash("Aa") != h.Hash("Aa") {
+			t.Fatal("expected seeded hash to be deterministic")
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		for _, seed := range []uint64{0, 1, 0xdeadbeef} {
+			m := NewMapWithSeed[string, int](nil, seed)
+			for i := 0; i < 10000; i++ {
+				m = m.Set(strconv.Itoa(i), i)
+			}
+			for i := 0; i < 10000; i++ {
+				if v, ok := m.Get(strconv.Itoa(i)); !ok || v != i {
+					t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+				}
+			}
+
+			// Derived maps keep the seeded hasher.
+			h := m.Hasher()
+			if sh, ok := h.(*seededHasher[string]); !ok || sh.seed != seed {
+				t.Fatalf("unexpected hasher: %#v", h)
+			} else if m.Delete("1").Set("foo", 1).Hasher() != h {
+				t.Fatal("expected derived map to keep hasher")
+			}
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		// Keys are equal when their values are equal modulo 10.
+		custom := &mockHasher[int]{
+			hash:  func(value int) uint32 { return hashUint64(uint64(value % 10)) },
+			equal: func(a, b int) bool { return a%10 == b%10 },
+		}
+		m := NewMapWithSeed[int, string](custom, 99).Set(3, "foo")
+		if v, ok := m.Get(13); !ok || v != "foo" {
+			t.Fatalf("Get(13)=<%v,%v>", v, ok)
+		} else if got := m.Set(23, "bar").Len(); got != 1 {
+			t.Fatalf("Len()=%d, expected 1", got)
+		}
+	})
+}
+
+func TestFloat64Hasher(t *testing.T) {
+	const n = 100000
+	m := NewMap[float64, int](Float64Hasher{})
+	for i := 0; i < n; i++ {
+		m = m.Set(float64(i)/7, i)
+	}
+	if m.Len() != n {
+		t.Fatalf("Len()=%d, expected %d", m.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(float64(i) / 7); !ok || v != i {
+			t.Fatalf("Get(%v)=<%v,%v>", float64(i)/7, v, ok)
+		}
+	}
+
+	t.Run("NegativeZero", func(t *testing.T) {
+		m := NewMap[float64, int](Float64Hasher{}).Set(0, 1)
+		if v, ok := m.Get(math.Copysign(0, -1)); !ok || v != 1 {
+			t.Fatalf("Get(-0)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		var h Float64Hasher
+		other := math.Float64frombits(math.Float64bits(math.NaN()) | 1) // different payload
+		if !h.Equal(math.NaN(), other) || h.Equal(math.NaN(), 1) {
+			t.Fatal("unexpected Equal() result for NaN")
+		} else if h.Hash(math.NaN()) != h.Hash(other) {
+			t.Fatal("expected NaN payloads to hash the same")
+		}
+
+		m := NewMap[float64, int](h).Set(math.NaN(), 1).Set(other, 2).Set(1, 3)
+		if m.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", m.Len())
+		} else if v, ok := m.Get(math.NaN()); !ok || v != 2 {
+			t.Fatalf("Get(NaN)=<%v,%v>", v, ok)
+		} else if m = m.Delete(math.NaN()); m.Len() != 1 {
+			t.Fatalf("Len()=%d after Delete(NaN), expected 1", m.Len())
+		}
+	})
+}
+
+func TestStringHasher(t *testing.T) {
+	var h StringHasher
+	large := strings.Repeat("abcdefghij", 100000)
+	for _, key := range []string{"", "a", "foo", large} {
+		if got, exp := h.HashBytes([]byte(key)), h.Hash(key); got != exp {
+			t.Fatalf("HashBytes(%.10q)=%d, expected %d", key, got, exp)
+		}
+	}
+	if got, exp := h.HashBytes(nil), h.Hash(""); got != exp {
+		t.Fatalf("HashBytes(nil)=%d, expected %d", got, exp)
+	} else if h.Hash("foo") == h.Hash("bar") {
+		t.Fatal("expected distinct hashes")
+	}
+
+	m := NewMap[string, int](h)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(fmt.Sprint(i), i)
+	}
+	m = m.Set("", -1).Set(large, -2)
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprint(i))
+		if v, ok := m.Get(string(key)); !ok || v != i {
+			t.Fatalf("Get(%q)=<%v,%v>", key, v, ok)
+		}
+	}
+	if v, ok := m.Get(string([]byte(nil))); !ok || v != -1 {
+		t.Fatalf("Get(nil)=<%v,%v>", v, ok)
+	} else if v, ok := m.Get(large); !ok || v != -2 {
+		t.Fatalf("Get(large)=<%v,%v>", v, ok)
+	}
+}
+
+func TestCaseInsensitiveString(t *testing.T) {
+	var h CaseInsensitiveStringHasher
+	var c CaseInsensitiveStringComparer
+
+	// Pairs that are equal under strings.EqualFold, including runes whose
+	// lowercase forms differ.
+	equal := [][2]string{
+		{"", ""},
+		{"Foo", "foo"},
+		{"FOO BAR", "foo bar"},
+		{"straße", "STRAßE"},
+		{"ſ", "S"},       // long s folds to s
+		{"\u212a", "k"},  // Kelvin sign folds to k
+		{"ς", "Σ"},       // final sigma folds to sigma
+		{"\xff", "\xfe"}, // invalid UTF-8 decodes as RuneError
+	}
+	for _, pair := range equal {
+		a, b := pair[0], pair[1]
+		if !h.Equal(a, b) {
+			t.Fatalf("Equal(%q, %q)=false", a, b)
+		} else if h.Hash(a) != h.Hash(b) {
+			t.Fatalf("Hash(%q)=%d, Hash(%q)=%d", a, h.Hash(a), b, h.Hash(b))
+		} else if cmp := c.Compare(a, b); cmp != 0 {
+			t.Fatalf("Compare(%q, %q)=%d", a, b, cmp)
+		}
+	}
+
+	// Dotted and dotless i do not fold to plain i.
+	unequal := [][2]string{
+		{"foo", "bar"},
+		{"foo", "fo"},
+		{"İ", "i"},
+		{"ı", "I"},
+		{"İ", "ı"},
+		{"a\x00", "a"},
+	}
+	for _, pair := range unequal {
+		a, b := pair[0], pair[1]
+		if h.Equal(a, b) {
+			t.Fatalf("Equal(%q, %q)=true", a, b)
+		} else if x, y := c.Compare(a, b), c.Compare(b, a); x == 0 || x != -y {
+			t.Fatalf("Compare(%q, %q)=%d, Compare(%q, %q)=%d", a, b, x, b, a, y)
+		}
+	}
+
+	if got := c.Compare("apple", "Banana"); got != -1 {
+		t.Fatalf("Compare(apple, Banana)=%d, expected -1", got)
+	} else if got := c.Compare("B", "a"); got != 1 {
+		t.Fatalf("Compare(B, a)=%d, expected 1", got)
+	}
+
+	t.Run("Map", func(t *testing.T) {
+		m := NewMap[string, int](h)
+		m = m.Set("Foo", 1).Set("foo", 2).Set("FOO", 3).Set("bar", 4)
+		if m.Len() != 2 {
+			t.Fatalf("Len()=%d, expected 2", m.Len())
+		} else if v, ok := m.Get("fOo"); !ok || v != 3 {
+			t.Fatalf("Get(fOo)=<%v,%v>", v, ok)
+		} else if k, _, _ := m.GetEntry("foo"); k != "FOO" {
+			t.Fatalf("GetEntry(foo) key=%q, expected FOO", k)
+		} else if _, ok := m.Get("İ"); ok {
+			t.Fatal("unexpected key")
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		m := NewSortedMap[string, int](c)
+		m = m.Set("b", 1).Set("A", 2).Set("B", 3).Set("c", 4)
+		if m.Len() != 3 {
+			t.Fatalf("Len()=%d, expected 3", m.Len())
+		} else if got, exp := fmt.Sprint(m.Keys()), "[A B c]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if v, ok := m.Get("C"); !ok || v != 4 {
+			t.Fatalf("Get(C)=<%v,%v>", v, ok)
+		}
+	})
+}
+
 // TSortedMap represents a combined immutable and stdlib sorted map.
 type TSortedMap struct {
 	im, prev *SortedMap[int, int]
@@ -2454,6 +5929,7 @@ func RunRandom(t *testing.T, name string, fn func(t *testing.T, rand *rand.Rand)
 	}
 	t.Run(name, func(t *testing.T) {
 		for i := 0; i < *randomN; i++ {
+			i := i
 			t.Run(fmt.Sprintf("%08d", i), func(t *testing.T) {
 				t.Parallel()
 				fn(t, rand.New(rand.NewSource(int64(i))))
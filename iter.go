@@ -0,0 +1,71 @@
+//go:build go1.23
+
+package immutable
+
+import "iter"
+
+// All returns an iterator over the elements of the list in index order.
+func (l *List[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		itr := l.Iterator()
+		for !itr.Done() {
+			if !yield(itr.Next()) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the key/value pairs of the map. The iteration
+// order is the same as the order used by MapIterator.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		itr := m.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the key/value pairs of the map in key order.
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		itr := m.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set. The iteration order
+// is unspecified.
+func (s Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		itr := s.m.Iterator()
+		for !itr.Done() {
+			val, _, _ := itr.Next()
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set in comparer order.
+func (s SortedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		itr := s.m.Iterator()
+		for !itr.Done() {
+			val, _, _ := itr.Next()
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+//go:build go1.23
+
+package immutable
+
+import (
+	"testing"
+)
+
+func TestList_All(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 100; i++ {
+		l = l.Append(i * 10)
+	}
+
+	var n int
+	for i, v := range l.All() {
+		if i != n || v != n*10 {
+			t.Fatalf("All() yielded <%d,%d>, expected <%d,%d>", i, v, n, n*10)
+		}
+		n++
+	}
+	if n != l.Len() {
+		t.Fatalf("All() yielded %d elements, expected %d", n, l.Len())
+	}
+
+	n = 0
+	for i := range l.All() {
+		if i == 9 {
+			break
+		}
+		n++
+	}
+	if n != 9 {
+		t.Fatalf("All() yielded %d elements before break, expected 9", n)
+	}
+}
+
+func TestMap_All(t *testing.T) {
+	m := NewMap[int, int](nil)
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i+1)
+	}
+
+	seen := make(map[int]struct{})
+	for k, v := range m.All() {
+		if v != k+1 {
+			t.Fatalf("All() yielded <%d,%d>, expected value %d", k, v, k+1)
+		}
+		seen[k] = struct{}{}
+	}
+	if len(seen) != m.Len() {
+		t.Fatalf("All() yielded %d keys, expected %d", len(seen), m.Len())
+	}
+
+	var n int
+	for range m.All() {
+		if n++; n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Fatalf("All() yielded %d entries before break, expected 10", n)
+	}
+}
+
+func TestSortedMap_All(t *testing.T) {
+	m := NewSortedMap[int, int](nil)
+	for i := 999; i >= 0; i-- {
+		m = m.Set(i, i+1)
+	}
+
+	var n int
+	for k, v := range m.All() {
+		if k != n || v != n+1 {
+			t.Fatalf("All() yielded <%d,%d>, expected <%d,%d>", k, v, n, n+1)
+		}
+		if n++; n == 500 {
+			break
+		}
+	}
+	if n != 500 {
+		t.Fatalf("All() yielded %d entries before break, expected 500", n)
+	}
+}
+
+func TestSet_All(t *testing.T) {
+	s := NewSetFromSlice(nil, []int{1, 2, 3, 4, 5})
+
+	other := NewSet[int](nil)
+	for v := range s.All() {
+		other = other.Set(v)
+	}
+	if !other.Equal(s) {
+		t.Fatalf("All() did not yield every element")
+	}
+
+	var n int
+	for range s.All() {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Fatalf("All() yielded %d elements before break, expected 2", n)
+	}
+}
+
+func TestSortedSet_All(t *testing.T) {
+	s := NewSortedSetFromSlice(nil, []string{"c", "a", "b"})
+
+	var a []string
+	for v := range s.All() {
+		a = append(a, v)
+	}
+	if len(a) != 3 || a[0] != "a" || a[1] != "b" || a[2] != "c" {
+		t.Fatalf("All() yielded %v, expected [a b c]", a)
+	}
+
+	a = a[:0]
+	for v := range s.All() {
+		if v == "b" {
+			break
+		}
+		a = append(a, v)
+	}
+	if len(a) != 1 {
+		t.Fatalf("All() yielded %v before break, expected [a]", a)
+	}
+}
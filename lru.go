@@ -0,0 +1,101 @@
+package immutable
+
+import "fmt"
+
+// LRU represents an immutable cache holding at most a fixed number of
+// key/value pairs. Adding a pair to a full cache evicts the least recently
+// used key. Every operation that changes recency returns a new cache so older
+// snapshots are unaffected.
+//
+// Values are stored in a Map along with the tick at which the key was last
+// used. Recency is tracked by a SortedMap from tick to key so that the least
+// recently used key is always the first entry and eviction is O(log n).
+type LRU[K comparable, V any] struct {
+	capacity int
+	entries  *Map[K, lruEntry[V]]
+	recency  *SortedMap[uint64, K]
+	tick     uint64 // last tick assigned
+}
+
+// lruEntry is the value stored in the entries map.
+type lruEntry[V any] struct {
+	tick  uint64
+	value V
+}
+
+// NewLRU returns a new cache holding at most capacity pairs. If hasher is nil
+// then a default hasher is chosen as for NewMap.
+//
+// This function will panic if capacity is not positive.
+func NewLRU[K comparable, V any](capacity int, hasher Hasher[K]) *LRU[K, V] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("immutable.NewLRU: invalid capacity %d", capacity))
+	}
+	return &LRU[K, V]{
+		capacity: capacity,
+		entries:  NewMap[K, lruEntry[V]](hasher),
+		recency:  NewSortedMap[uint64, K](nil),
+	}
+}
+
+// Len returns the number of pairs in the cache.
+func (c *LRU[K, V]) Len() int {
+	return c.entries.Len()
+}
+
+// Cap returns the maximum number of pairs the cache holds.
+func (c *LRU[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Peek returns the value for key without marking it as recently used.
+func (c *LRU[K, V]) Peek(key K) (value V, ok bool) {
+	e, ok := c.entries.Get(key)
+	return e.value, ok
+}
+
+// Get returns the value for key and a new cache with key marked as the most
+// recently used. Returns false and the original cache if the key does not
+// exist.
+func (c *LRU[K, V]) Get(key K) (value V, other *LRU[K, V], ok bool) {
+	e, ok := c.entries.Get(key)
+	if !ok {
+		return value, c, false
+	}
+	return e.value, c.touch(key, e, true), true
+}
+
+// Put returns a new cache with key set to value and marked as the most
+// recently used. If the key is new and the cache is full then the least
+// recently used key is evicted.
+func (c *LRU[K, V]) Put(key K, value V) *LRU[K, V] {
+	e, ok := c.entries.Get(key)
+	e.value = value
+	other := c.touch(key, e, ok)
+	if !ok && other.entries.Len() > other.capacity {
+		oldest, _ := other.recency.MinKey()
+		evicted, _ := other.recency.Get(oldest)
+		other.recency = other.recency.Delete(oldest)
+		other.entries = other.entries.Delete(evicted)
+	}
+	return other
+}
+
+// Keys returns the keys in the cache from least to most recently used.
+func (c *LRU[K, V]) Keys() []K {
+	return c.recency.Values()
+}
+
+// touch returns a copy of c with key stored as e at a new tick. If the key
+// already exists then e.tick is its previous tick.
+func (c *LRU[K, V]) touch(key K, e lruEntry[V], exists bool) *LRU[K, V] {
+	other := *c
+	if exists {
+		other.recency = other.recency.Delete(e.tick)
+	}
+	other.tick++
+	e.tick = other.tick
+	other.entries = other.entries.Set(key, e)
+	other.recency = other.recency.Set(e.tick, key)
+	return &other
+}
@@ -0,0 +1,95 @@
+package immutable
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	keys := func(c *LRU[string, int]) string { return fmt.Sprint(c.Keys()) }
+
+	t.Run("Eviction", func(t *testing.T) {
+		c := NewLRU[string, int](3, nil)
+		c = c.Put("a", 1).Put("b", 2).Put("c", 3)
+		if got, exp := keys(c), "[a b c]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		}
+
+		// Adding to a full cache evicts the least recently used keys in order.
+		d := c.Put("d", 4)
+		if got, exp := keys(d), "[b c d]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if _, ok := d.Peek("a"); ok {
+			t.Fatal("expected a to be evicted")
+		}
+		e := d.Put("e", 5)
+		if got, exp := keys(e), "[c d e]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if e.Len() != 3 || e.Cap() != 3 {
+			t.Fatalf("Len()=%d, Cap()=%d", e.Len(), e.Cap())
+		}
+
+		// Older snapshots are unaffected.
+		if got, exp := keys(c), "[a b c]"; got != exp {
+			t.Fatalf("original Keys()=%s, expected %s", got, exp)
+		} else if v, ok := c.Peek("a"); !ok || v != 1 {
+			t.Fatalf("original Peek(a)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("Reaccess", func(t *testing.T) {
+		c := NewLRU[string, int](3, nil).Put("a", 1).Put("b", 2).Put("c", 3)
+
+		// Get marks a as recently used so b is evicted next.
+		v, other, ok := c.Get("a")
+		if !ok || v != 1 {
+			t.Fatalf("Get(a)=<%v,%v>", v, ok)
+		} else if got, exp := keys(other), "[b c a]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if got, exp := keys(other.Put("d", 4)), "[c a d]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		}
+
+		// Updating an existing key marks it as recently used without evicting.
+		updated := c.Put("b", 20)
+		if got, exp := keys(updated), "[a c b]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if v, ok := updated.Peek("b"); !ok || v != 20 {
+			t.Fatalf("Peek(b)=<%v,%v>", v, ok)
+		}
+
+		// Peek and missing keys do not change recency.
+		if _, ok := c.Peek("a"); !ok {
+			t.Fatal("expected Peek(a) to succeed")
+		} else if _, other, ok := c.Get("z"); ok || other != c {
+			t.Fatal("expected Get() of a missing key to return the original cache")
+		} else if got, exp := keys(c), "[a b c]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		}
+	})
+
+	t.Run("CapacityOne", func(t *testing.T) {
+		c := NewLRU[string, int](1, nil).Put("a", 1)
+		c = c.Put("b", 2)
+		if got, exp := keys(c), "[b]"; got != exp {
+			t.Fatalf("Keys()=%s, expected %s", got, exp)
+		} else if _, ok := c.Peek("a"); ok {
+			t.Fatal("expected a to be evicted")
+		} else if c = c.Put("b", 3); c.Len() != 1 {
+			t.Fatalf("Len()=%d, expected 1", c.Len())
+		} else if v, _, ok := c.Get("b"); !ok || v != 3 {
+			t.Fatalf("Get(b)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("InvalidCapacity", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			NewLRU[string, int](0, nil)
+		}()
+		if r != `immutable.NewLRU: invalid capacity 0` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package immutable
+
+// MultiMap represents an immutable map from each key to a set of values. It is
+// backed by a Map from key to Set so that adding a value which is already
+// present for a key has no effect. A key is removed once its last value is.
+type MultiMap[K, V comparable] struct {
+	m           *Map[K, Set[V]]
+	valueHasher Hasher[V]
+}
+
+// NewMultiMap returns a new instance of MultiMap. keyHasher and valueHasher
+// are used for the keys and for each key's set of values respectively. If
+// either is nil then a default hasher is chosen as for NewMap.
+func NewMultiMap[K, V comparable](keyHasher Hasher[K], valueHasher Hasher[V]) *MultiMap[K, V] {
+	return &MultiMap[K, V]{
+		m:           NewMap[K, Set[V]](keyHasher),
+		valueHasher: valueHasher,
+	}
+}
+
+// Len returns the number of keys in the map.
+func (m *MultiMap[K, V]) Len() int {
+	return m.m.Len()
+}
+
+// Get returns the set of values for key. Returns an empty set if the key does
+// not exist.
+func (m *MultiMap[K, V]) Get(key K) Set[V] {
+	if values, ok := m.m.Get(key); ok {
+		return values
+	}
+	return NewSet(m.valueHasher)
+}
+
+// Has returns true if value is one of the values for key.
+func (m *MultiMap[K, V]) Has(key K, value V) bool {
+	values, ok := m.m.Get(key)
+	return ok && values.Has(value)
+}
+
+// Add returns a map with value added to the values for key. Returns the
+// original map if the value is already present.
+func (m *MultiMap[K, V]) Add(key K, value V) *MultiMap[K, V] {
+	values, ok := m.m.Get(key)
+	if !ok {
+		values = NewSet(m.valueHasher)
+	} else if values.Has(value) {
+		return m
+	}
+	return &MultiMap[K, V]{m: m.m.Set(key, values.Set(value)), valueHasher: m.valueHasher}
+}
+
+// Remove returns a map with value removed from the values for key. The key is
+// removed entirely when its last value is removed. Returns the original map if
+// the value is not present.
+func (m *MultiMap[K, V]) Remove(key K, value V) *MultiMap[K, V] {
+	values, ok := m.m.Get(key)
+	if !ok || !values.Has(value) {
+		return m
+	}
+	if values = values.Delete(value); values.Len() == 0 {
+		return m.RemoveKey(key)
+	}
+	return &MultiMap[K, V]{m: m.m.Set(key, values), valueHasher: m.valueHasher}
+}
+
+// RemoveKey returns a map with key and all of its values removed. Returns the
+// original map if the key does not exist.
+func (m *MultiMap[K, V]) RemoveKey(key K) *MultiMap[K, V] {
+	other := m.m.Delete(key)
+	if other == m.m {
+		return m
+	}
+	return &MultiMap[K, V]{m: other, valueHasher: m.valueHasher}
+}
+
+// Iterator returns an iterator over each key and its set of values.
+func (m *MultiMap[K, V]) Iterator() *MapIterator[K, Set[V]] {
+	return m.m.Iterator()
+}
@@ -0,0 +1,94 @@
+package immutable
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	// values returns the sorted values for key.
+	values := func(m *MultiMap[string, int], key string) []int {
+		a := m.Get(key).ToSlice()
+		sort.Ints(a)
+		return a
+	}
+	equal := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	m := NewMultiMap[string, int](nil, nil)
+	m = m.Add("a", 1).Add("a", 2).Add("b", 3).Add("a", 3)
+	if m.Len() != 2 {
+		t.Fatalf("Len()=%d, expected 2", m.Len())
+	} else if got, exp := values(m, "a"), []int{1, 2, 3}; !equal(got, exp) {
+		t.Fatalf("Get(a)=%v, expected %v", got, exp)
+	} else if got, exp := values(m, "b"), []int{3}; !equal(got, exp) {
+		t.Fatalf("Get(b)=%v, expected %v", got, exp)
+	} else if !m.Has("a", 2) || m.Has("b", 2) || m.Has("c", 1) {
+		t.Fatal("unexpected Has() result")
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		if m.Add("a", 2) != m {
+			t.Fatal("expected original map when adding a duplicate value")
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		if s := m.Get("c"); s.Len() != 0 {
+			t.Fatalf("Get(c).Len()=%d", s.Len())
+		} else if s.Set(1).Len() != 1 {
+			t.Fatal("expected empty set to be usable")
+		} else if m.Remove("a", 10) != m || m.Remove("c", 1) != m || m.RemoveKey("c") != m {
+			t.Fatal("expected original map when removing a missing value")
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		other := m.Remove("a", 2)
+		if got, exp := values(other, "a"), []int{1, 3}; !equal(got, exp) {
+			t.Fatalf("Get(a)=%v, expected %v", got, exp)
+		} else if got, exp := values(m, "a"), []int{1, 2, 3}; !equal(got, exp) {
+			t.Fatalf("original Get(a)=%v, expected %v", got, exp)
+		}
+
+		// Removing the last value removes the key.
+		other = other.Remove("b", 3)
+		if other.Len() != 1 {
+			t.Fatalf("Len()=%d, expected 1", other.Len())
+		} else if _, ok := other.m.Get("b"); ok {
+			t.Fatal("expected key to be removed with its last value")
+		}
+	})
+
+	t.Run("RemoveKey", func(t *testing.T) {
+		other := m.RemoveKey("a")
+		if other.Len() != 1 || other.Get("a").Len() != 0 {
+			t.Fatalf("Len()=%d, Get(a).Len()=%d", other.Len(), other.Get("a").Len())
+		} else if m.Get("a").Len() != 3 {
+			t.Fatal("unexpected mutation of original map")
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		var n int
+		for itr := m.Iterator(); !itr.Done(); {
+			k, s, _ := itr.Next()
+			if !s.Equal(m.Get(k)) {
+				t.Fatalf("unexpected values for %q", k)
+			}
+			n += s.Len()
+		}
+		if n != 4 {
+			t.Fatalf("total values=%d, expected 4", n)
+		}
+	})
+}
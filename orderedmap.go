@@ -0,0 +1,149 @@
+package immutable
+
+// OrderedMap represents an immutable hash map that iterates over its
+// key/value pairs in the order keys were first inserted. Setting an existing
+// key updates its value but keeps its original position.
+//
+// It wraps a Map from each key to its value and position, plus a List of keys
+// in insertion order. Deleting a key marks its slot in the list as deleted so
+// that Set, Get, and Delete stay O(log n). The list is compacted once deleted
+// slots outnumber live ones so its size stays proportional to the map.
+type OrderedMap[K comparable, V any] struct {
+	m     *Map[K, orderedMapEntry[V]]
+	order *List[orderedMapSlot[K]]
+}
+
+// orderedMapEntry is the value stored in the underlying map.
+type orderedMapEntry[V any] struct {
+	index int // position of the key's slot in the order list
+	value V
+}
+
+// orderedMapSlot is an element of the insertion order list.
+type orderedMapSlot[K any] struct {
+	key     K
+	deleted bool
+}
+
+// NewOrderedMap returns a new instance of OrderedMap. If hasher is nil, a
+// default hasher is chosen as for NewMap.
+func NewOrderedMap[K comparable, V any](hasher Hasher[K]) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		m:     NewMap[K, orderedMapEntry[V]](hasher),
+		order: NewList[orderedMapSlot[K]](),
+	}
+}
+
+// Len returns the number of elements in the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return m.m.Len()
+}
+
+// Get returns the value for a given key and a flag indicating whether the
+// key exists.
+func (m *OrderedMap[K, V]) Get(key K) (value V, ok bool) {
+	e, ok := m.m.Get(key)
+	return e.value, ok
+}
+
+// Set returns a map with the key set to the new value. A new key is added to
+// the end of the iteration order; an existing key keeps its position.
+func (m *OrderedMap[K, V]) Set(key K, value V) *OrderedMap[K, V] {
+	if e, ok := m.m.Get(key); ok {
+		e.value = value
+		return &OrderedMap[K, V]{m: m.m.Set(key, e), order: m.order}
+	}
+
+	e := orderedMapEntry[V]{index: m.order.Len(), value: value}
+	return &OrderedMap[K, V]{
+		m:     m.m.Set(key, e),
+		order: m.order.Append(orderedMapSlot[K]{key: key}),
+	}
+}
+
+// Delete returns a map with the given key removed. Returns the original map
+// if the key does not exist.
+func (m *OrderedMap[K, V]) Delete(key K) *OrderedMap[K, V] {
+	e, ok := m.m.Get(key)
+	if !ok {
+		return m
+	}
+
+	other := &OrderedMap[K, V]{
+		m:     m.m.Delete(key),
+		order: m.order.Set(e.index, orderedMapSlot[K]{deleted: true}),
+	}
+	if other.order.Len() > 2*other.m.Len() {
+		other.compact()
+	}
+	return other
+}
+
+// compact rebuilds the map and order list without deleted slots.
+func (m *OrderedMap[K, V]) compact() {
+	entries := NewMapBuilder[K, orderedMapEntry[V]](m.m.hasher)
+	order := NewListBuilder[orderedMapSlot[K]]()
+	itr := m.order.Iterator()
+	for !itr.Done() {
+		_, slot := itr.Next()
+		if slot.deleted {
+			continue
+		}
+		e, _ := m.m.Get(slot.key)
+		e.index = order.Len()
+		entries.Set(slot.key, e)
+		order.Append(slot)
+	}
+	m.m, m.order = entries.Map(), order.List()
+}
+
+// Iterator returns a new iterator for the map positioned at the first key in
+// insertion order.
+func (m *OrderedMap[K, V]) Iterator() *OrderedMapIterator[K, V] {
+	itr := &OrderedMapIterator[K, V]{m: m}
+	itr.First()
+	return itr
+}
+
+// OrderedMapIterator represents an iterator over an ordered map's key/value
+// pairs in insertion order.
+type OrderedMapIterator[K comparable, V any] struct {
+	m    *OrderedMap[K, V]                // source map
+	itr  *ListIterator[orderedMapSlot[K]] // iterator over the order list
+	next orderedMapSlot[K]                // next live slot
+	done bool
+}
+
+// Done returns true if no more elements remain in the iterator.
+func (itr *OrderedMapIterator[K, V]) Done() bool {
+	return itr.done
+}
+
+// First resets the iterator to the first key/value pair.
+func (itr *OrderedMapIterator[K, V]) First() {
+	itr.itr = itr.m.order.Iterator()
+	itr.advance()
+}
+
+// Next returns the next key/value pair. Returns a false flag when no elements
+// remain.
+func (itr *OrderedMapIterator[K, V]) Next() (key K, value V, ok bool) {
+	if itr.done {
+		return key, value, false
+	}
+	key = itr.next.key
+	value, _ = itr.m.Get(key)
+	itr.advance()
+	return key, value, true
+}
+
+// advance moves to the next slot that has not been deleted.
+func (itr *OrderedMapIterator[K, V]) advance() {
+	for !itr.itr.Done() {
+		if _, slot := itr.itr.Next(); !slot.deleted {
+			itr.next, itr.done = slot, false
+			return
+		}
+	}
+	itr.next, itr.done = orderedMapSlot[K]{}, true
+}
@@ -0,0 +1,127 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	// keys returns the keys of m in iteration order.
+	keys := func(m *OrderedMap[string, int]) []string {
+		var a []string
+		for itr := m.Iterator(); !itr.Done(); {
+			k, v, ok := itr.Next()
+			if !ok {
+				t.Fatal("expected ok")
+			} else if exp, _ := m.Get(k); v != exp {
+				t.Fatalf("Next() value=%d for %q, expected %d", v, k, exp)
+			}
+			a = append(a, k)
+		}
+		return a
+	}
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	t.Run("InsertUpdateDelete", func(t *testing.T) {
+		m := NewOrderedMap[string, int](nil)
+		m = m.Set("c", 1).Set("a", 2).Set("b", 3)
+		if got, exp := keys(m), []string{"c", "a", "b"}; !equal(got, exp) {
+			t.Fatalf("keys=%v, expected %v", got, exp)
+		}
+
+		// Updating an existing key keeps its position.
+		updated := m.Set("c", 10)
+		if got, exp := keys(updated), []string{"c", "a", "b"}; !equal(got, exp) {
+			t.Fatalf("keys=%v, expected %v", got, exp)
+		} else if v, ok := updated.Get("c"); !ok || v != 10 {
+			t.Fatalf("Get(c)=<%v,%v>", v, ok)
+		} else if v, _ := m.Get("c"); v != 1 {
+			t.Fatalf("original Get(c)=%v", v)
+		}
+
+		// Deleting and re-adding moves the key to the end.
+		deleted := updated.Delete("c")
+		if got, exp := keys(deleted), []string{"a", "b"}; !equal(got, exp) {
+			t.Fatalf("keys=%v, expected %v", got, exp)
+		} else if _, ok := deleted.Get("c"); ok || deleted.Len() != 2 {
+			t.Fatalf("Get(c) ok=%v, Len()=%d", ok, deleted.Len())
+		}
+		readded := deleted.Set("c", 4)
+		if got, exp := keys(readded), []string{"a", "b", "c"}; !equal(got, exp) {
+			t.Fatalf("keys=%v, expected %v", got, exp)
+		} else if got, exp := keys(updated), []string{"c", "a", "b"}; !equal(got, exp) {
+			t.Fatalf("original keys=%v, expected %v", got, exp)
+		}
+
+		if deleted.Delete("missing") != deleted {
+			t.Fatal("expected original map when deleting a missing key")
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		m := NewOrderedMap[string, int](nil)
+		itr := m.Iterator()
+		if !itr.Done() {
+			t.Fatal("expected iterator to be done")
+		} else if _, _, ok := itr.Next(); ok {
+			t.Fatal("expected Next() to fail")
+		}
+		if m = m.Set("a", 1).Delete("a"); m.Len() != 0 || len(keys(m)) != 0 {
+			t.Fatalf("Len()=%d, keys=%v", m.Len(), keys(m))
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		rand := rand.New(rand.NewSource(0))
+		m := NewOrderedMap[string, int](nil)
+		var order []string
+		values := make(map[string]int)
+		for i := 0; i < 10000; i++ {
+			key := string(rune('a' + rand.Intn(200)))
+			if rand.Intn(3) == 0 {
+				m = m.Delete(key)
+				if _, ok := values[key]; ok {
+					delete(values, key)
+					for j := range order {
+						if order[j] == key {
+							order = append(order[:j], order[j+1:]...)
+							break
+						}
+					}
+				}
+			} else {
+				m = m.Set(key, i)
+				if _, ok := values[key]; !ok {
+					order = append(order, key)
+				}
+				values[key] = i
+			}
+
+			if m.Len() != len(values) {
+				t.Fatalf("Len()=%d, expected %d", m.Len(), len(values))
+			} else if m.order.Len() > 2*m.Len()+1 {
+				t.Fatalf("order list not compacted: %d slots for %d keys", m.order.Len(), m.Len())
+			}
+			if i%100 == 0 {
+				if got := keys(m); !equal(got, order) {
+					t.Fatalf("keys=%v, expected %v", got, order)
+				}
+				for k, exp := range values {
+					if v, ok := m.Get(k); !ok || v != exp {
+						t.Fatalf("Get(%q)=<%v,%v>, expected %d", k, v, ok, exp)
+					}
+				}
+			}
+		}
+	})
+}
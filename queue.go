@@ -0,0 +1,72 @@
+package immutable
+
+// Queue represents an immutable first-in, first-out collection. It is
+// implemented as a banker's queue: elements are dequeued from a front list and
+// enqueued onto a back list which is kept in reverse order. When the front
+// list runs out, the back list is reversed to become the new front.
+//
+// Both lists are persistent singly linked lists so Enqueue and Dequeue are
+// amortized O(1). Each element is reversed at most once for a given sequence
+// of operations, although dequeuing the same older snapshot repeatedly may
+// repeat its reversal.
+type Queue[T any] struct {
+	front *queueNode[T] // next elements to dequeue, in order
+	back  *queueNode[T] // most recently enqueued elements, in reverse order
+	size  int           // total number of elements
+}
+
+// queueNode represents an element in one of the lists of a queue.
+type queueNode[T any] struct {
+	value T
+	next  *queueNode[T]
+}
+
+// NewQueue returns a new queue containing values. The first value is at the
+// front of the queue.
+func NewQueue[T any](values ...T) Queue[T] {
+	var front *queueNode[T]
+	for i := len(values) - 1; i >= 0; i-- {
+		front = &queueNode[T]{value: values[i], next: front}
+	}
+	return Queue[T]{front: front, size: len(values)}
+}
+
+// Len returns the number of elements in the queue.
+func (q Queue[T]) Len() int {
+	return q.size
+}
+
+// Enqueue returns a new queue with value added to the back.
+func (q Queue[T]) Enqueue(value T) Queue[T] {
+	// The front is only empty if the queue is, so Peek never reverses.
+	if q.front == nil {
+		return Queue[T]{front: &queueNode[T]{value: value}, size: 1}
+	}
+	return Queue[T]{front: q.front, back: &queueNode[T]{value: value, next: q.back}, size: q.size + 1}
+}
+
+// Dequeue returns the front element and a new queue without it. Returns false
+// and the original queue if the queue is empty.
+func (q Queue[T]) Dequeue() (value T, other Queue[T], ok bool) {
+	if q.front == nil {
+		return value, q, false
+	}
+
+	other = Queue[T]{front: q.front.next, back: q.back, size: q.size - 1}
+	if other.front == nil {
+		for n := q.back; n != nil; n = n.next {
+			other.front = &queueNode[T]{value: n.value, next: other.front}
+		}
+		other.back = nil
+	}
+	return q.front.value, other, true
+}
+
+// Peek returns the front element without removing it. Returns false if the
+// queue is empty.
+func (q Queue[T]) Peek() (value T, ok bool) {
+	if q.front == nil {
+		return value, false
+	}
+	return q.front.value, true
+}
@@ -0,0 +1,96 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	rand := rand.New(rand.NewSource(0))
+
+	// Interleave enqueues and dequeues and compare against a slice.
+	type snapshot struct {
+		q   Queue[int]
+		exp []int
+	}
+	var snapshots []snapshot
+	q, exp := NewQueue[int](), []int(nil)
+	for i := 0; i < 10000; i++ {
+		if rand.Intn(3) == 0 {
+			v, other, ok := q.Dequeue()
+			if len(exp) == 0 {
+				if ok || other != q {
+					t.Fatal("expected Dequeue() on empty queue to fail")
+				}
+				continue
+			} else if !ok || v != exp[0] {
+				t.Fatalf("Dequeue()=<%v,%v>, expected %d", v, ok, exp[0])
+			}
+			q, exp = other, exp[1:]
+		} else {
+			q, exp = q.Enqueue(i), append(exp, i)
+		}
+
+		if q.Len() != len(exp) {
+			t.Fatalf("Len()=%d, expected %d", q.Len(), len(exp))
+		} else if v, ok := q.Peek(); ok != (len(exp) > 0) || (ok && v != exp[0]) {
+			t.Fatalf("Peek()=<%v,%v>", v, ok)
+		}
+		if i%1000 == 0 {
+			snapshots = append(snapshots, snapshot{q: q, exp: append([]int(nil), exp...)})
+		}
+	}
+
+	// Older snapshots still dequeue their original contents.
+	for i, snap := range snapshots {
+		q := snap.q
+		for _, exp := range snap.exp {
+			v, other, ok := q.Dequeue()
+			if !ok || v != exp {
+				t.Fatalf("snapshot %d: Dequeue()=<%v,%v>, expected %d", i, v, ok, exp)
+			}
+			q = other
+		}
+		if q.Len() != 0 {
+			t.Fatalf("snapshot %d: Len()=%d after draining", i, q.Len())
+		}
+	}
+
+	// Each element is moved from the back list to the front list at most once.
+	t.Run("Reverse", func(t *testing.T) {
+		q := NewQueue[int]()
+		for i := 0; i < 5; i++ {
+			q = q.Enqueue(i)
+		}
+		if q.front == nil || q.front.next != nil || q.back == nil {
+			t.Fatal("expected first element at the front and the rest at the back")
+		}
+		_, q, _ = q.Dequeue()
+		if q.back != nil || q.Len() != 4 {
+			t.Fatal("expected back list to be reversed onto the front")
+		}
+		for exp := 1; exp < 5; exp++ {
+			if v, other, ok := q.Dequeue(); !ok || v != exp {
+				t.Fatalf("Dequeue()=<%v,%v>, expected %d", v, ok, exp)
+			} else if other.back != nil {
+				t.Fatal("unexpected back list")
+			} else {
+				q = other
+			}
+		}
+	})
+
+	t.Run("NewQueue", func(t *testing.T) {
+		q := NewQueue(1, 2, 3)
+		for exp := 1; exp <= 3; exp++ {
+			v, other, ok := q.Dequeue()
+			if !ok || v != exp {
+				t.Fatalf("Dequeue()=<%v,%v>, expected %d", v, ok, exp)
+			}
+			q = other
+		}
+		if _, ok := q.Peek(); ok {
+			t.Fatal("expected Peek() on empty queue to fail")
+		}
+	})
+}
@@ -1,7 +1,16 @@
 package immutable
 
+import (
+	"context"
+	"math/rand"
+)
+
+// Set represents an immutable set of values backed by a Map. The zero value is
+// an empty set that is safe for reads, but values can only be added to a set
+// created with NewSet or NewSetFromSlice.
 type Set[T comparable] struct {
-	m *Map[T, struct{}]
+	m     *Map[T, struct{}]
+	bloom *setBloom // optional filter, see WithBloom
 }
 
 func NewSet[T comparable](hasher Hasher[T]) Set[T] {
@@ -10,16 +19,130 @@ func NewSet[T comparable](hasher Hasher[T]) Set[T] {
 	}
 }
 
+// NewSetFromSlice returns a new set containing each of the given values.
+// Duplicate values are only stored once.
+func NewSetFromSlice[T comparable](hasher Hasher[T], values []T) Set[T] {
+	b := NewSetBuilder(hasher)
+	for _, val := range values {
+		b.Set(val)
+	}
+	return b.s
+}
+
 func (s Set[T]) Set(val T) Set[T] {
-	return Set[T]{
-		m: s.m.Set(val, struct{}{}),
+	if s.m == nil {
+		panic("immutable.Set.Set: cannot add to zero-value set, use NewSet")
 	}
+	other := Set[T]{
+		m:     s.m.Set(val, struct{}{}),
+		bloom: s.bloom,
+	}
+	if other.bloom != nil {
+		if hash := other.m.hasher.Hash(val); !other.bloom.has(hash) {
+			other.bloom = other.bloom.clone()
+			other.bloom.add(hash)
+		}
+	}
+	return other
 }
 
 func (s Set[T]) Delete(val T) Set[T] {
+	// Bits cannot be removed from a bloom filter but a stale bit only causes
+	// a false positive so the filter is kept as is.
 	return Set[T]{
-		m: s.m.Delete(val),
+		m:     s.m.Delete(val),
+		bloom: s.bloom,
+	}
+}
+
+// WithBloom returns a copy of the set with a bloom filter of the given number
+// of bits, rounded up to a multiple of 64, used by MaybeHas. If bits is zero or
+// less then the copy has no filter.
+//
+// The filter is carried over by Set, Delete, SetMany, and DeleteMany. Adding
+// values copies the filter whenever it adds bits so it costs O(bits) for new
+// elements; the filter is best suited to large sets which are queried far more
+// often than they are changed. Sets produced by other methods do not have a
+// filter.
+func (s Set[T]) WithBloom(bits int) Set[T] {
+	if bits <= 0 {
+		return Set[T]{m: s.m}
+	}
+	bloom := &setBloom{bits: make([]uint64, (bits+63)/64)}
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		bloom.add(s.m.hasher.Hash(val))
+	}
+	return Set[T]{m: s.m, bloom: bloom}
+}
+
+// MaybeHas returns the same result as Has. If the set has a bloom filter, see
+// WithBloom, then values which are definitely absent are rejected without
+// searching the set.
+func (s Set[T]) MaybeHas(val T) bool {
+	if s.bloom != nil && s.m.hasher != nil && !s.bloom.has(s.m.hasher.Hash(val)) {
+		return false
+	}
+	return s.Has(val)
+}
+
+// SetMany returns a set with each of the given values added. Returns the
+// original set if no values are given.
+//
+// The values are added by a builder seeded from s, which copies each node of s
+// the first time a value is added beneath it and updates the copy in-place
+// after that.
+func (s Set[T]) SetMany(values ...T) Set[T] {
+	if len(values) == 0 {
+		return s
+	} else if s.m == nil {
+		panic("immutable.Set.SetMany: cannot add to zero-value set, use NewSet")
+	}
+
+	b := s.builder()
+	for _, val := range values {
+		b.Set(val)
+	}
+	other := b.Build()
+
+	// Add every value to a copy of the filter, if any.
+	if s.bloom != nil {
+		other.bloom = s.bloom.clone()
+		for _, val := range values {
+			other.bloom.add(other.m.hasher.Hash(val))
+		}
+	}
+	return other
+}
+
+// DeleteMany returns a set with each of the given values removed. Values that
+// do not exist in the set are ignored. Returns the original set if no values
+// are given or none of them exist.
+//
+// As with SetMany, the values are removed by a builder seeded from s. The
+// bloom filter, if any, is kept as with Delete.
+func (s Set[T]) DeleteMany(values ...T) Set[T] {
+	if len(values) == 0 || s.Len() == 0 {
+		return s
 	}
+
+	b := s.builder()
+	for _, val := range values {
+		b.Delete(val)
+	}
+	if b.Len() == s.Len() {
+		return s
+	}
+	other := b.Build()
+	other.bloom = s.bloom
+	return other
+}
+
+// builder returns a builder seeded with the contents of s. Nodes of s are
+// copied before they are updated so s is not affected.
+func (s Set[T]) builder() *SetBuilder[T] {
+	return &SetBuilder[T]{s: Set[T]{m: s.m.clone()}}
 }
 
 func (s Set[T]) Has(val T) bool {
@@ -31,6 +154,307 @@ func (s Set[K]) Len() int {
 	return s.m.Len()
 }
 
+// Clone returns the set. Sets are immutable so no copy is required and the
+// result can be shared freely between goroutines.
+func (s Set[T]) Clone() Set[T] {
+	return s
+}
+
+// Clear returns an empty set that uses the same hasher as s. Returns the
+// original set if it is already empty.
+func (s Set[T]) Clear() Set[T] {
+	if s.Len() == 0 {
+		return s
+	}
+	return Set[T]{m: s.m.Clear()}
+}
+
+// Union returns a set containing every element of s and other. Elements of
+// the smaller set are added to the larger one so the result shares structure
+// with the larger set. If either set is empty then the other is returned.
+//
+// Both sets are expected to use equivalent hashers. The result uses the hasher
+// of the larger set (the receiver, if both are the same size) so elements from
+// a set with a different hasher are rehashed and their equality is determined
+// by the larger set's hasher.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	if other.Len() == 0 {
+		return s
+	} else if s.Len() == 0 {
+		return other
+	}
+
+	larger, smaller := s, other
+	if smaller.Len() > larger.Len() {
+		larger, smaller = smaller, larger
+	}
+
+	m := larger.m
+	itr := smaller.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if _, ok := m.Get(val); !ok {
+			m = m.Set(val, struct{}{})
+		}
+	}
+	return Set[T]{m: m}
+}
+
+// Intersection returns a set containing only the elements present in both s
+// and other. The smaller set is iterated and each element is checked against
+// the larger set. If every element of the smaller set is present in the larger
+// set then the smaller set is returned as-is.
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	if s.m == other.m {
+		return s
+	}
+
+	larger, smaller := s, other
+	if smaller.Len() > larger.Len() {
+		larger, smaller = smaller, larger
+	}
+	if smaller.Len() == 0 {
+		return smaller
+	}
+
+	b := NewSetBuilder(smaller.m.hasher)
+	itr := smaller.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if larger.Has(val) {
+			b.Set(val)
+		}
+	}
+	if b.Len() == smaller.Len() {
+		return smaller
+	}
+	return b.s
+}
+
+// Difference returns a set containing the elements of s that are not present
+// in other. If no elements are removed then s is returned as-is.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	if s.Len() == 0 || other.Len() == 0 {
+		return s
+	}
+
+	b := NewSetBuilder(s.m.hasher)
+	if s.m != other.m {
+		itr := s.m.Iterator()
+		for !itr.Done() {
+			val, _, _ := itr.Next()
+			if !other.Has(val) {
+				b.Set(val)
+			}
+		}
+	}
+	if b.Len() == s.Len() {
+		return s
+	}
+	return b.s
+}
+
+// SymmetricDifference returns a set containing the elements present in
+// exactly one of s and other. Each set is iterated once and elements missing
+// from the opposite set are collected into a single builder.
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	if other.Len() == 0 {
+		return s
+	} else if s.Len() == 0 {
+		return other
+	}
+
+	b := NewSetBuilder(s.m.hasher)
+	if s.m != other.m {
+		for _, pair := range [2][2]Set[T]{{s, other}, {other, s}} {
+			itr := pair[0].m.Iterator()
+			for !itr.Done() {
+				val, _, _ := itr.Next()
+				if !pair[1].Has(val) {
+					b.Set(val)
+				}
+			}
+		}
+	}
+	return b.s
+}
+
+// Equal returns true if s and other contain the same elements.
+// Insertion order does not affect equality.
+func (s Set[T]) Equal(other Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
+
+// IsSubset returns true if every element of s is present in other.
+// The empty set is a subset of every set, including itself.
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	} else if s.m == other.m {
+		return true
+	}
+
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if !other.Has(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if every element of other is present in s.
+func (s Set[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Filter returns a set containing only the elements for which pred returns
+// true. If every element is retained then s is returned as-is.
+func (s Set[T]) Filter(pred func(val T) bool) Set[T] {
+	if s.Len() == 0 {
+		return s
+	}
+
+	b := NewSetBuilder(s.m.hasher)
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if pred(val) {
+			b.Set(val)
+		}
+	}
+	if b.Len() == s.Len() {
+		return s
+	}
+	return b.s
+}
+
+// Count returns the number of elements for which pred returns true.
+func (s Set[T]) Count(pred func(val T) bool) int {
+	return s.m.Count(func(val T, _ struct{}) bool {
+		return pred(val)
+	})
+}
+
+// Any returns true if pred returns true for any element of the set. Iteration
+// stops at the first match. Returns false for an empty set.
+func (s Set[T]) Any(pred func(val T) bool) bool {
+	var found bool
+	s.ForEach(func(val T) bool {
+		found = pred(val)
+		return !found
+	})
+	return found
+}
+
+// Every returns true if pred returns true for every element of the set.
+// Iteration stops at the first element that does not match. Returns true for
+// an empty set.
+func (s Set[T]) Every(pred func(val T) bool) bool {
+	return !s.Any(func(val T) bool { return !pred(val) })
+}
+
+// ForEach calls f for each element of the set. Iteration stops early if f
+// returns false. The order of elements is unspecified.
+func (s Set[T]) ForEach(f func(val T) bool) {
+	s.m.ForEach(func(val T, _ struct{}) bool {
+		return f(val)
+	})
+}
+
+// Stream returns a channel which receives every element of the set and is then
+// closed. The set is immutable so the elements sent are a consistent snapshot
+// even if the caller derives new sets while reading. If ctx is cancelled then
+// no more elements are sent and the channel is closed, so the goroutine
+// sending elements does not leak when the caller stops reading early.
+func (s Set[T]) Stream(ctx context.Context) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		itr := s.m.Iterator()
+		for !itr.Done() && ctx.Err() == nil {
+			val, _, _ := itr.Next()
+			select {
+			case ch <- val:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ToSlice returns a new slice containing every element of the set. The order
+// of elements is unspecified and may differ between sets with the same
+// elements if they were built in a different order.
+func (s Set[T]) ToSlice() []T {
+	vals := make([]T, 0, s.Len())
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+// Sample returns up to n elements of s chosen uniformly at random using
+// reservoir sampling in a single pass over the set. If n is at least Len then
+// every element is returned. If rng is nil then the default source from the
+// math/rand package is used.
+func (s Set[T]) Sample(n int, rng *rand.Rand) []T {
+	if n <= 0 {
+		return nil
+	}
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+
+	size := n
+	if size > s.Len() {
+		size = s.Len()
+	}
+	vals := make([]T, 0, size)
+	itr := s.m.Iterator()
+	for i := 0; !itr.Done(); i++ {
+		val, _, _ := itr.Next()
+		if i < n {
+			vals = append(vals, val)
+		} else if j := intn(i + 1); j < n {
+			vals[j] = val
+		}
+	}
+	return vals
+}
+
+// Sorted returns a SortedSet containing every element of s, ordered by
+// comparer. If comparer is nil then a default comparer is used, as with
+// NewSortedMap.
+func (s Set[T]) Sorted(comparer Comparer[T]) SortedSet[T] {
+	b := NewSortedSetBuilder(comparer)
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		b.Set(val)
+	}
+	return b.s
+}
+
+// String returns a string representation of the set in iteration order, such
+// as "Set{a, b, c}". Only the first 100 elements are included.
+func (s Set[T]) String() string {
+	w := newElemWriter("Set")
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if !w.write("%v", val) {
+			break
+		}
+	}
+	return w.String()
+}
+
 func (s Set[T]) Iterator() *SetIterator[T] {
 	itr := &SetIterator[T]{mi: s.m.Iterator()}
 	itr.mi.First()
@@ -49,35 +473,88 @@ func (itr *SetIterator[T]) First() {
 	itr.mi.First()
 }
 
+// Last moves the iterator to the last element that Next would return.
+func (itr *SetIterator[T]) Last() {
+	itr.mi.Last()
+}
+
 func (itr *SetIterator[T]) Next() (val T, ok bool) {
 	val, _, ok = itr.mi.Next()
 	return
 }
 
+// Prev returns the current element and moves the iterator backward, in the
+// reverse of the order used by Next.
+func (itr *SetIterator[T]) Prev() (val T, ok bool) {
+	val, _, ok = itr.mi.Prev()
+	return
+}
+
+// SetMap returns a new set containing the result of f for each element of src.
+// The result uses hasher, or a default hasher if nil. Since f may map distinct
+// elements to the same value, the result may be smaller than src.
+func SetMap[T, U comparable](src Set[T], hasher Hasher[U], f func(val T) U) Set[U] {
+	b := NewSetBuilder(hasher)
+	src.ForEach(func(val T) bool {
+		b.Set(f(val))
+		return true
+	})
+	return b.s
+}
+
+// SetBuilder represents an efficient builder for creating Sets.
+// A SetBuilder is not safe for concurrent use.
 type SetBuilder[T comparable] struct {
-	s Set[T]
+	s        Set[T]
+	sizeHint int
 }
 
 func NewSetBuilder[T comparable](hasher Hasher[T]) *SetBuilder[T] {
 	return &SetBuilder[T]{s: NewSet(hasher)}
 }
 
-func (s SetBuilder[T]) Set(val T) {
+// NewSetBuilderSized returns a new instance of SetBuilder for a set that is
+// expected to hold about sizeHint elements. See NewMapBuilderSized.
+func NewSetBuilderSized[T comparable](hasher Hasher[T], sizeHint int) *SetBuilder[T] {
+	return &SetBuilder[T]{s: NewSet(hasher), sizeHint: sizeHint}
+}
+
+// Build returns the underlying set. Only call once.
+// Builder is invalid after call. Will panic on second invocation.
+func (s *SetBuilder[T]) Build() Set[T] {
+	assert(s.s.m != nil, "immutable.SetBuilder.Build(): duplicate call to fetch set")
+	set := s.s
+	s.s.m = nil
+	return set
+}
+
+func (s *SetBuilder[T]) Set(val T) {
+	assert(s.s.m != nil, "immutable.SetBuilder: builder invalid after Build() invocation")
+	if s.s.m.root == nil && s.sizeHint > maxBitmapIndexedSize {
+		s.s.m.initHashArrayRoot(val, struct{}{})
+		return
+	}
 	s.s.m = s.s.m.set(val, struct{}{}, true)
 }
 
-func (s SetBuilder[T]) Delete(val T) {
+func (s *SetBuilder[T]) Delete(val T) {
+	assert(s.s.m != nil, "immutable.SetBuilder: builder invalid after Build() invocation")
 	s.s.m = s.s.m.delete(val, true)
 }
 
-func (s SetBuilder[T]) Has(val T) bool {
+func (s *SetBuilder[T]) Has(val T) bool {
+	assert(s.s.m != nil, "immutable.SetBuilder: builder invalid after Build() invocation")
 	return s.s.Has(val)
 }
 
-func (s SetBuilder[T]) Len() int {
+func (s *SetBuilder[T]) Len() int {
+	assert(s.s.m != nil, "immutable.SetBuilder: builder invalid after Build() invocation")
 	return s.s.Len()
 }
 
+// SortedSet represents an immutable set of values backed by a SortedMap. As
+// with Set, the zero value is an empty set that is safe for reads but values
+// can only be added to a set created with NewSortedSet.
 type SortedSet[T comparable] struct {
 	m *SortedMap[T, struct{}]
 }
@@ -88,7 +565,20 @@ func NewSortedSet[T comparable](comparer Comparer[T]) SortedSet[T] {
 	}
 }
 
+// NewSortedSetFromSlice returns a new sorted set containing each of the given
+// values. Duplicate values are only stored once.
+func NewSortedSetFromSlice[T comparable](comparer Comparer[T], values []T) SortedSet[T] {
+	b := NewSortedSetBuilder(comparer)
+	for _, val := range values {
+		b.Set(val)
+	}
+	return b.s
+}
+
 func (s SortedSet[T]) Put(val T) SortedSet[T] {
+	if s.m == nil {
+		panic("immutable.SortedSet.Put: cannot add to zero-value set, use NewSortedSet")
+	}
 	return SortedSet[T]{
 		m: s.m.Set(val, struct{}{}),
 	}
@@ -109,6 +599,224 @@ func (s SortedSet[K]) Len() int {
 	return s.m.Len()
 }
 
+// Clone returns the set. Sets are immutable so no copy is required and the
+// result can be shared freely between goroutines.
+func (s SortedSet[T]) Clone() SortedSet[T] {
+	return s
+}
+
+// Clear returns an empty set that uses the same comparer as s. Returns the
+// original set if it is already empty.
+func (s SortedSet[T]) Clear() SortedSet[T] {
+	if s.Len() == 0 {
+		return s
+	}
+	return SortedSet[T]{m: s.m.Clear()}
+}
+
+// ToSlice returns a new slice containing every element of the set in the
+// order defined by the set's comparer.
+func (s SortedSet[T]) ToSlice() []T {
+	vals := make([]T, 0, s.Len())
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+// Unsorted returns a Set containing every element of s, hashed by hasher. If
+// hasher is nil then a default hasher is used, as with NewMap.
+func (s SortedSet[T]) Unsorted(hasher Hasher[T]) Set[T] {
+	b := NewSetBuilderSized(hasher, s.Len())
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		b.Set(val)
+	}
+	return b.s
+}
+
+// Min returns the lowest element in the set. Returns false if the set is empty.
+func (s SortedSet[T]) Min() (T, bool) {
+	return s.m.MinKey()
+}
+
+// Max returns the highest element in the set. Returns false if the set is empty.
+func (s SortedSet[T]) Max() (T, bool) {
+	return s.m.MaxKey()
+}
+
+// Rank returns the number of elements in the set that are less than val. val
+// does not need to exist in the set. The tree does not track subtree sizes so
+// this is O(n) in the rank returned.
+func (s SortedSet[T]) Rank(val T) int {
+	var n int
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		if v, _, _ := itr.Next(); s.m.comparer.Compare(v, val) != -1 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// Select returns the k-th smallest element of the set, starting from zero.
+// Returns false if k is out of range. The tree does not track subtree sizes
+// so this iterates from the nearer end of the set.
+func (s SortedSet[T]) Select(k int) (val T, ok bool) {
+	if k < 0 || k >= s.Len() {
+		return val, false
+	}
+
+	itr := s.m.Iterator()
+	if k < s.Len()/2 {
+		for ; k > 0; k-- {
+			itr.Next()
+		}
+		val, _, ok = itr.Next()
+		return val, ok
+	}
+
+	itr.Last()
+	for k = s.Len() - 1 - k; k > 0; k-- {
+		itr.Prev()
+	}
+	val, _, ok = itr.Prev()
+	return val, ok
+}
+
+// Range returns a new set containing the elements in the half-open range
+// [lo, hi): elements greater than or equal to lo and strictly less than hi.
+// Neither bound needs to exist in the set. If lo is not less than hi then an
+// empty set is returned.
+func (s SortedSet[T]) Range(lo, hi T) SortedSet[T] {
+	if s.Len() == 0 {
+		return s
+	}
+
+	b := NewSortedSetBuilder(s.m.comparer)
+	s.m.RangeFunc(lo, hi, func(val T, _ struct{}) bool {
+		b.Set(val)
+		return true
+	})
+	return b.s
+}
+
+// Union returns a set containing every element of s and other. If either set
+// is empty, or other is a subset of s, then the other set or s is returned.
+//
+// Both sets are expected to use equivalent comparers. Elements are merged in
+// order in a single pass over both sets and the result uses the comparer of s.
+func (s SortedSet[T]) Union(other SortedSet[T]) SortedSet[T] {
+	if other.Len() == 0 {
+		return s
+	} else if s.Len() == 0 {
+		return other
+	}
+	return s.merge(other, true, true, true)
+}
+
+// Intersection returns a set containing only the elements present in both s
+// and other. If every element of s is present in other then s is returned.
+// See Union for comparer requirements.
+func (s SortedSet[T]) Intersection(other SortedSet[T]) SortedSet[T] {
+	if s.m == other.m || s.Len() == 0 {
+		return s
+	} else if other.Len() == 0 {
+		return s.Clear()
+	}
+	return s.merge(other, false, true, false)
+}
+
+// Difference returns a set containing the elements of s that are not present
+// in other. If no elements are removed then s is returned. See Union for
+// comparer requirements.
+func (s SortedSet[T]) Difference(other SortedSet[T]) SortedSet[T] {
+	if s.Len() == 0 || other.Len() == 0 {
+		return s
+	} else if s.m == other.m {
+		return s.Clear()
+	}
+	return s.merge(other, true, false, false)
+}
+
+// SymmetricDifference returns a set containing the elements present in exactly
+// one of s and other. See Union for comparer requirements.
+func (s SortedSet[T]) SymmetricDifference(other SortedSet[T]) SortedSet[T] {
+	if other.Len() == 0 {
+		return s
+	} else if s.Len() == 0 {
+		return other
+	}
+	return s.merge(other, true, false, true)
+}
+
+// merge iterates s and other in order and returns a set containing the elements
+// found only in s if left is true, in both sets if both is true, and only in
+// other if right is true. Returns s if the result contains every element of s.
+func (s SortedSet[T]) merge(other SortedSet[T], left, both, right bool) SortedSet[T] {
+	comparer := s.m.comparer
+	if comparer == nil {
+		comparer = other.m.comparer
+	}
+	b := NewSortedSetBuilder(comparer)
+
+	sitr, oitr := s.m.Iterator(), other.m.Iterator()
+	x, _, xok := sitr.Next()
+	y, _, yok := oitr.Next()
+	for (xok || right) && (yok || left) && (xok || yok) {
+		cmp := -1
+		if !xok {
+			cmp = 1
+		} else if yok {
+			cmp = comparer.Compare(x, y)
+		}
+
+		switch {
+		case cmp < 0:
+			if left {
+				b.Set(x)
+			}
+			x, _, xok = sitr.Next()
+		case cmp > 0:
+			if right {
+				b.Set(y)
+			}
+			y, _, yok = oitr.Next()
+		default:
+			if both {
+				b.Set(x)
+			}
+			x, _, xok = sitr.Next()
+			y, _, yok = oitr.Next()
+		}
+	}
+
+	// The result is a subset of s when right is false and a superset of s when
+	// left and both are true. Either way it equals s if the lengths match.
+	if b.Len() == s.Len() && (!right || (left && both)) {
+		return s
+	}
+	return b.s
+}
+
+// String returns a string representation of the set in sorted order, such as
+// "SortedSet{a, b, c}". Only the first 100 elements are included.
+func (s SortedSet[T]) String() string {
+	w := newElemWriter("SortedSet")
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		val, _, _ := itr.Next()
+		if !w.write("%v", val) {
+			break
+		}
+	}
+	return w.String()
+}
+
 func (s SortedSet[T]) Iterator() *SortedSetIterator[T] {
 	itr := &SortedSetIterator[T]{mi: s.m.Iterator()}
 	itr.mi.First()
@@ -145,6 +853,8 @@ func (itr *SortedSetIterator[T]) Seek(val T) {
 	itr.mi.Seek(val)
 }
 
+// SortedSetBuilder represents an efficient builder for creating SortedSets.
+// A SortedSetBuilder is not safe for concurrent use.
 type SortedSetBuilder[T comparable] struct {
 	s SortedSet[T]
 }
@@ -153,18 +863,68 @@ func NewSortedSetBuilder[T comparable](comparer Comparer[T]) *SortedSetBuilder[T
 	return &SortedSetBuilder[T]{s: NewSortedSet(comparer)}
 }
 
-func (s SortedSetBuilder[T]) Set(val T) {
+// Build returns the underlying set. Only call once.
+// Builder is invalid after call. Will panic on second invocation.
+func (s *SortedSetBuilder[T]) Build() SortedSet[T] {
+	assert(s.s.m != nil, "immutable.SortedSetBuilder.Build(): duplicate call to fetch set")
+	set := s.s
+	s.s.m = nil
+	return set
+}
+
+func (s *SortedSetBuilder[T]) Set(val T) {
+	assert(s.s.m != nil, "immutable.SortedSetBuilder: builder invalid after Build() invocation")
 	s.s.m = s.s.m.set(val, struct{}{}, true)
 }
 
-func (s SortedSetBuilder[T]) Delete(val T) {
+func (s *SortedSetBuilder[T]) Delete(val T) {
+	assert(s.s.m != nil, "immutable.SortedSetBuilder: builder invalid after Build() invocation")
 	s.s.m = s.s.m.delete(val, true)
 }
 
-func (s SortedSetBuilder[T]) Has(val T) bool {
+func (s *SortedSetBuilder[T]) Has(val T) bool {
+	assert(s.s.m != nil, "immutable.SortedSetBuilder: builder invalid after Build() invocation")
 	return s.s.Has(val)
 }
 
-func (s SortedSetBuilder[T]) Len() int {
+func (s *SortedSetBuilder[T]) Len() int {
+	assert(s.s.m != nil, "immutable.SortedSetBuilder: builder invalid after Build() invocation")
 	return s.s.Len()
 }
+
+// setBloomProbes is the number of bits set in a bloom filter for each element.
+const setBloomProbes = 3
+
+// setBloom is a bloom filter of element hashes. A filter attached to a set is
+// never modified so it is cloned before adding bits.
+type setBloom struct {
+	bits []uint64
+}
+
+// clone returns a copy of the filter.
+func (b *setBloom) clone() *setBloom {
+	return &setBloom{bits: append([]uint64(nil), b.bits...)}
+}
+
+// add sets the bits for hash.
+func (b *setBloom) add(hash uint32) {
+	n := uint32(len(b.bits) * 64)
+	h2 := mixHash(uint64(hash)) | 1
+	for i := uint32(0); i < setBloomProbes; i++ {
+		pos := (hash + i*h2) % n
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// has returns false if the bits for hash are not all set.
+func (b *setBloom) has(hash uint32) bool {
+	n := uint32(len(b.bits) * 64)
+	h2 := mixHash(uint64(hash)) | 1
+	for i := uint32(0); i < setBloomProbes; i++ {
+		pos := (hash + i*h2) % n
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
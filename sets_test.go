@@ -1,7 +1,13 @@
 package immutable
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSetsPut(t *testing.T) {
@@ -49,6 +55,89 @@ func TestSetsDelete(t *testing.T) {
 	}
 }
 
+func TestSetSetMany(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{1, 2, 3})
+	if other := s.SetMany(); other.m != s.m {
+		t.Fatal("expected original set when adding no values")
+	}
+
+	// Adding values copies each shared node at most once.
+	t.Run("CopyOnWrite", func(t *testing.T) {
+		large := NewSet[int](nil)
+		for i := 0; i < 10000; i++ {
+			large = large.Set(i)
+		}
+		nodes := make(map[mapNode[int, struct{}]]struct{})
+		large.m.walk(func(n mapNode[int, struct{}], depth int) { nodes[n] = struct{}{} })
+
+		values := make([]int, 100)
+		for i := range values {
+			values[i] = 10000 + i
+		}
+		other := large.SetMany(values...)
+
+		var added int
+		other.m.walk(func(n mapNode[int, struct{}], depth int) {
+			if _, ok := nodes[n]; !ok {
+				added++
+			}
+		})
+		if depth := large.m.Stats().Depth; added > len(values)*depth {
+			t.Fatalf("SetMany() added %d nodes, expected at most %d", added, len(values)*depth)
+		} else if other.Len() != 10100 || large.Len() != 10000 || large.Has(10000) {
+			t.Fatalf("unexpected Len()=%d, original Len()=%d", other.Len(), large.Len())
+		}
+	})
+
+	// Exercise both adding fewer and more values than the set contains.
+	for _, values := range [][]int{{3, 4}, {3, 4, 5, 6, 7}} {
+		other := s.SetMany(values...)
+		if got, exp := other.Len(), len(values)+2; got != exp {
+			t.Fatalf("Len()=%d, expected %d", got, exp)
+		}
+		for _, v := range append([]int{1, 2}, values...) {
+			if !other.Has(v) {
+				t.Fatalf("expected %d in set", v)
+			}
+		}
+		if got, exp := s.Len(), 3; got != exp {
+			t.Fatalf("original set modified: Len()=%d, expected %d", got, exp)
+		} else if s.Has(4) {
+			t.Fatal("original set modified")
+		}
+	}
+}
+
+func TestSetDeleteMany(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{1, 2, 3, 4})
+	if other := s.DeleteMany(); other.m != s.m {
+		t.Fatal("expected original set when deleting no values")
+	} else if other := s.DeleteMany(5, 6, 7, 8, 9); other.m != s.m {
+		t.Fatal("expected original set when deleting missing values")
+	}
+
+	for _, tt := range []struct {
+		values []int
+		n      int
+	}{{[]int{2, 5}, 3}, {[]int{1, 2, 3, 5, 6}, 1}} {
+		values := tt.values
+		other := s.DeleteMany(values...)
+		if got, exp := other.Len(), tt.n; got != exp {
+			t.Fatalf("Len()=%d, expected %d", got, exp)
+		}
+		for _, v := range values {
+			if other.Has(v) {
+				t.Fatalf("unexpected %d in set", v)
+			}
+		}
+		if !other.Has(4) {
+			t.Fatal("expected 4 in set")
+		} else if got, exp := s.Len(), 4; got != exp {
+			t.Fatalf("original set modified: Len()=%d, expected %d", got, exp)
+		}
+	}
+}
+
 func TestSortedSetsPut(t *testing.T) {
 	s := NewSortedSet[string](nil)
 	s2 := s.Put("1").Put("1").Put("0")
@@ -100,3 +189,1242 @@ func TestSortedSetsDelete(t *testing.T) {
 		t.Fatalf("Unexpected set element after delete")
 	}
 }
+
+func TestSetBuilder(t *testing.T) {
+	const n = 5000
+	b := NewSetBuilder[int](nil)
+	for i := 0; i < n; i++ {
+		b.Set(i)
+	}
+	if b.Len() != n {
+		t.Fatalf("SetBuilder.Len()=%d, expected %d", b.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if !b.Has(i) {
+			t.Fatalf("SetBuilder.Has(%d)=false, expected true", i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		b.Delete(i)
+	}
+	if b.Len() != n/2 {
+		t.Fatalf("SetBuilder.Len()=%d, expected %d after delete", b.Len(), n/2)
+	}
+	for i := 0; i < n; i++ {
+		if b.Has(i) != (i%2 == 1) {
+			t.Fatalf("SetBuilder.Has(%d)=%v after delete", i, b.Has(i))
+		}
+	}
+}
+
+func TestSetBuilder_Build(t *testing.T) {
+	b := NewSetBuilder[int](nil)
+	b.Set(1)
+	b.Set(2)
+	s := b.Build()
+
+	var r string
+	func() {
+		defer func() { r = recover().(string) }()
+		b.Set(3)
+	}()
+	if r != `immutable.SetBuilder: builder invalid after Build() invocation` {
+		t.Fatalf("unexpected panic: %q", r)
+	}
+	func() {
+		defer func() { r = recover().(string) }()
+		b.Build()
+	}()
+	if r != `immutable.SetBuilder.Build(): duplicate call to fetch set` {
+		t.Fatalf("unexpected panic: %q", r)
+	}
+	if s.Len() != 2 || !s.Has(1) || !s.Has(2) || s.Has(3) {
+		t.Fatalf("unexpected set: %v", s)
+	}
+}
+
+func TestNewSetBuilderSized(t *testing.T) {
+	b := NewSetBuilderSized[int](nil, 1000)
+	for i := 0; i < 100; i++ {
+		b.Set(i)
+	}
+	s := b.s
+	if got, exp := s.Len(), 100; got != exp {
+		t.Fatalf("Len()=%d, expected %d", got, exp)
+	} else if got := len(s.ToSlice()); got != 100 {
+		t.Fatalf("len(ToSlice())=%d, expected 100", got)
+	}
+	for i := 0; i < 100; i++ {
+		if !s.Has(i) {
+			t.Fatalf("Has(%d)=false, expected true", i)
+		}
+	}
+}
+
+func TestSortedSetBuilder(t *testing.T) {
+	const n = 5000
+	b := NewSortedSetBuilder[int](nil)
+	for i := n - 1; i >= 0; i-- {
+		b.Set(i)
+	}
+	if b.Len() != n {
+		t.Fatalf("SortedSetBuilder.Len()=%d, expected %d", b.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if !b.Has(i) {
+			t.Fatalf("SortedSetBuilder.Has(%d)=false, expected true", i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		b.Delete(i)
+	}
+	if b.Len() != n/2 {
+		t.Fatalf("SortedSetBuilder.Len()=%d, expected %d after delete", b.Len(), n/2)
+	}
+	for i := 0; i < n; i++ {
+		if b.Has(i) != (i%2 == 1) {
+			t.Fatalf("SortedSetBuilder.Has(%d)=%v after delete", i, b.Has(i))
+		}
+	}
+}
+
+func TestSortedSetBuilder_Build(t *testing.T) {
+	b := NewSortedSetBuilder[int](nil)
+	b.Set(2)
+	b.Set(1)
+	s := b.Build()
+
+	var r string
+	func() {
+		defer func() { r = recover().(string) }()
+		b.Delete(1)
+	}()
+	if r != `immutable.SortedSetBuilder: builder invalid after Build() invocation` {
+		t.Fatalf("unexpected panic: %q", r)
+	}
+	if got := s.ToSlice(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("ToSlice()=%v", got)
+	}
+}
+
+func TestSetUnion(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		s := NewSet[int](nil).Set(1).Set(2)
+		if u := s.Union(NewSet[int](nil)); u.m != s.m {
+			t.Fatalf("expected receiver to be returned when unioned with empty set")
+		}
+		if u := NewSet[int](nil).Union(s); u.m != s.m {
+			t.Fatalf("expected other set to be returned when receiver is empty")
+		}
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		a := NewSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			a = a.Set(i)
+		}
+		b := NewSet[int](nil)
+		for i := 500; i < 1200; i++ {
+			b = b.Set(i)
+		}
+
+		u := a.Union(b)
+		if u.Len() != 1200 {
+			t.Fatalf("Union().Len()=%d, expected 1200", u.Len())
+		}
+		for i := 0; i < 1200; i++ {
+			if !u.Has(i) {
+				t.Fatalf("Union() missing element %d", i)
+			}
+		}
+		if a.Len() != 1000 || b.Len() != 700 {
+			t.Fatalf("unexpected mutation of source sets: %d, %d", a.Len(), b.Len())
+		}
+		if b.Union(a).Len() != 1200 {
+			t.Fatalf("Union() not commutative")
+		}
+	})
+}
+
+func BenchmarkSet_Union(b *testing.B) {
+	const n = 10000
+	large, small := NewSet[int](nil), NewSet[int](nil)
+	for i := 0; i < n; i++ {
+		large = large.Set(i)
+	}
+	for i := n - 100; i < n+100; i++ {
+		small = small.Set(i)
+	}
+
+	b.Run("Union", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			small.Union(large)
+		}
+	})
+
+	b.Run("Naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := small
+			itr := large.Iterator()
+			for !itr.Done() {
+				v, _ := itr.Next()
+				s = s.Set(v)
+			}
+		}
+	})
+}
+
+func BenchmarkSet_SetMany(b *testing.B) {
+	base := NewSet[int](nil)
+	for i := 0; i < 10000; i++ {
+		base = base.Set(i)
+	}
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = 10000 + i
+	}
+
+	b.Run("SetMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			base.SetMany(values...)
+		}
+	})
+
+	b.Run("Individual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := base
+			for _, v := range values {
+				s = s.Set(v)
+			}
+		}
+	})
+
+	b.Run("SetManyEmpty", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewSet[int](nil).SetMany(values...)
+		}
+	})
+
+	b.Run("IndividualEmpty", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := NewSet[int](nil)
+			for _, v := range values {
+				s = s.Set(v)
+			}
+		}
+	})
+}
+
+func BenchmarkSet_DeleteMany(b *testing.B) {
+	base := NewSet[int](nil)
+	for i := 0; i < 1000; i++ {
+		base = base.Set(i)
+	}
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i * 2
+	}
+
+	b.Run("DeleteMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			base.DeleteMany(values...)
+		}
+	})
+
+	b.Run("Individual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := base
+			for _, v := range values {
+				s = s.Delete(v)
+			}
+		}
+	})
+}
+
+func BenchmarkSet_MaybeHas(b *testing.B) {
+	const n = 100000
+	base := NewSetBuilder[int](nil)
+	for i := 0; i < n; i++ {
+		base.Set(i)
+	}
+	s := base.Build().WithBloom(n * 16)
+
+	b.Run("Has", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Has(n + i)
+		}
+	})
+
+	b.Run("MaybeHas", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.MaybeHas(n + i)
+		}
+	})
+}
+
+func TestSetIntersection(t *testing.T) {
+	t.Run("Disjoint", func(t *testing.T) {
+		a := NewSet[int](nil).Set(1).Set(2).Set(3)
+		b := NewSet[int](nil).Set(4).Set(5)
+		if i := a.Intersection(b); i.Len() != 0 {
+			t.Fatalf("Intersection().Len()=%d, expected 0", i.Len())
+		} else if i.Has(1) || i.Has(4) {
+			t.Fatalf("unexpected element in empty intersection")
+		}
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		a := NewSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			a = a.Set(i)
+		}
+		b := NewSet[int](nil)
+		for i := 900; i < 1200; i++ {
+			b = b.Set(i)
+		}
+
+		for _, i := range []Set[int]{a.Intersection(b), b.Intersection(a)} {
+			if i.Len() != 100 {
+				t.Fatalf("Intersection().Len()=%d, expected 100", i.Len())
+			}
+			for v := 900; v < 1000; v++ {
+				if !i.Has(v) {
+					t.Fatalf("Intersection() missing element %d", v)
+				}
+			}
+		}
+		if a.Len() != 1000 || b.Len() != 300 {
+			t.Fatalf("unexpected mutation of source sets: %d, %d", a.Len(), b.Len())
+		}
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		a := NewSet[string](nil).Set("a").Set("b")
+		if i := a.Intersection(a); i.m != a.m {
+			t.Fatalf("expected self-intersection to return the receiver")
+		}
+		b := NewSet[string](nil).Set("b").Set("a")
+		if i := a.Intersection(b); i.Len() != 2 || !i.Has("a") || !i.Has("b") {
+			t.Fatalf("unexpected intersection of equal sets: len=%d", i.Len())
+		}
+	})
+}
+
+func TestSetDifference(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		a := NewSet[int](nil).Set(1).Set(2)
+		if d := a.Difference(NewSet[int](nil)); d.Len() != 2 || !d.Has(1) || !d.Has(2) {
+			t.Fatalf("unexpected difference against empty set: len=%d", d.Len())
+		}
+		if d := NewSet[int](nil).Difference(a); d.Len() != 0 {
+			t.Fatalf("Difference().Len()=%d, expected 0", d.Len())
+		}
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		a := NewSet[int](nil).Set(1).Set(2)
+		if d := a.Difference(a); d.Len() != 0 || d.Has(1) || d.Has(2) {
+			t.Fatalf("expected empty set, got len=%d", d.Len())
+		}
+		if a.Len() != 2 {
+			t.Fatalf("unexpected mutation of set")
+		}
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		a := NewSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			a = a.Set(i)
+		}
+		b := NewSet[int](nil)
+		for i := 500; i < 1500; i++ {
+			b = b.Set(i)
+		}
+
+		d := a.Difference(b)
+		if d.Len() != 500 {
+			t.Fatalf("Difference().Len()=%d, expected 500", d.Len())
+		}
+		for i := 0; i < 1500; i++ {
+			if d.Has(i) != (i < 500) {
+				t.Fatalf("Difference().Has(%d)=%v", i, d.Has(i))
+			}
+		}
+		if a.Len() != 1000 || !a.Has(999) {
+			t.Fatalf("unexpected mutation of receiver")
+		}
+		if b.Len() != 1000 || !b.Has(500) {
+			t.Fatalf("unexpected mutation of other set")
+		}
+	})
+}
+
+func TestSetSymmetricDifference(t *testing.T) {
+	t.Run("Overlap", func(t *testing.T) {
+		a := NewSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			a = a.Set(i)
+		}
+		b := NewSet[int](nil)
+		for i := 500; i < 1500; i++ {
+			b = b.Set(i)
+		}
+
+		for _, d := range []Set[int]{a.SymmetricDifference(b), b.SymmetricDifference(a)} {
+			if d.Len() != 1000 {
+				t.Fatalf("SymmetricDifference().Len()=%d, expected 1000", d.Len())
+			}
+			for i := 0; i < 1500; i++ {
+				if d.Has(i) != (i < 500 || i >= 1000) {
+					t.Fatalf("SymmetricDifference().Has(%d)=%v", i, d.Has(i))
+				}
+			}
+		}
+	})
+
+	t.Run("Disjoint", func(t *testing.T) {
+		a := NewSet[string](nil).Set("a").Set("b")
+		b := NewSet[string](nil).Set("c")
+		d := a.SymmetricDifference(b)
+		if d.Len() != 3 || !d.Has("a") || !d.Has("b") || !d.Has("c") {
+			t.Fatalf("unexpected symmetric difference of disjoint sets: len=%d", d.Len())
+		}
+	})
+
+	t.Run("Identical", func(t *testing.T) {
+		a := NewSet[string](nil).Set("a").Set("b")
+		b := NewSet[string](nil).Set("b").Set("a")
+		if d := a.SymmetricDifference(b); d.Len() != 0 {
+			t.Fatalf("SymmetricDifference().Len()=%d, expected 0", d.Len())
+		}
+		if d := a.SymmetricDifference(a); d.Len() != 0 {
+			t.Fatalf("SymmetricDifference().Len()=%d, expected 0 for self", d.Len())
+		}
+	})
+}
+
+func TestSetEqual(t *testing.T) {
+	a, b := NewSet[int](nil), NewSet[int](nil)
+	for i := 0; i < 1000; i++ {
+		a = a.Set(i)
+		b = b.Set(999 - i)
+	}
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Fatalf("expected sets built in different orders to be equal")
+	}
+	if !a.Equal(a) {
+		t.Fatalf("expected set to equal itself")
+	}
+	if a.Equal(b.Delete(0)) {
+		t.Fatalf("expected sets of different length to be unequal")
+	}
+	if a.Equal(b.Delete(0).Set(1000)) {
+		t.Fatalf("expected sets with different elements to be unequal")
+	}
+	if !NewSet[int](nil).Equal(NewSet[int](nil)) {
+		t.Fatalf("expected empty sets to be equal")
+	}
+}
+
+func TestSetIsSubset(t *testing.T) {
+	empty := NewSet[int](nil)
+	a := NewSet[int](nil).Set(1).Set(2)
+	b := NewSet[int](nil).Set(1).Set(2).Set(3)
+
+	if !empty.IsSubset(empty) || !empty.IsSubset(a) {
+		t.Fatalf("expected empty set to be a subset of every set")
+	}
+	if !a.IsSubset(b) || b.IsSubset(a) {
+		t.Fatalf("unexpected result for proper subset")
+	}
+	if !a.IsSubset(a) || !a.IsSubset(NewSet[int](nil).Set(2).Set(1)) {
+		t.Fatalf("expected improper subset")
+	}
+	if a.IsSubset(NewSet[int](nil).Set(1).Set(3)) {
+		t.Fatalf("unexpected subset of disjoint elements")
+	}
+
+	if !b.IsSuperset(a) || a.IsSuperset(b) {
+		t.Fatalf("unexpected result for proper superset")
+	}
+	if !a.IsSuperset(a) || !a.IsSuperset(empty) || empty.IsSuperset(a) {
+		t.Fatalf("unexpected result for improper superset")
+	}
+}
+
+func TestNewSetFromSlice(t *testing.T) {
+	if s := NewSetFromSlice[int](nil, nil); s.Len() != 0 {
+		t.Fatalf("Len()=%d, expected 0 for nil slice", s.Len())
+	}
+	if s := NewSetFromSlice(nil, []int{}); s.Len() != 0 || s.Has(0) {
+		t.Fatalf("Len()=%d, expected 0 for empty slice", s.Len())
+	}
+
+	s := NewSetFromSlice(nil, []string{"a", "b", "a", "c", "b"})
+	if s.Len() != 3 {
+		t.Fatalf("Len()=%d, expected 3", s.Len())
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if !s.Has(v) {
+			t.Fatalf("Set element %q missing", v)
+		}
+	}
+}
+
+func TestNewSortedSetFromSlice(t *testing.T) {
+	if s := NewSortedSetFromSlice[int](nil, nil); s.Len() != 0 {
+		t.Fatalf("Len()=%d, expected 0 for nil slice", s.Len())
+	}
+
+	s := NewSortedSetFromSlice(nil, []int{3, 1, 2, 3, 1})
+	if s.Len() != 3 {
+		t.Fatalf("Len()=%d, expected 3", s.Len())
+	}
+	itr := s.Iterator()
+	for exp := 1; exp <= 3; exp++ {
+		if v, ok := itr.Next(); !ok || v != exp {
+			t.Fatalf("Next()=<%v,%v>, expected %d", v, ok, exp)
+		}
+	}
+	if !itr.Done() {
+		t.Fatalf("expected iterator done")
+	}
+}
+
+func TestSetToSlice(t *testing.T) {
+	if a := NewSet[int](nil).ToSlice(); a == nil || len(a) != 0 {
+		t.Fatalf("ToSlice()=%#v, expected empty slice", a)
+	}
+
+	s := NewSet[int](nil)
+	for i := 0; i < 1000; i++ {
+		s = s.Set(i)
+	}
+	a := s.ToSlice()
+	if len(a) != s.Len() {
+		t.Fatalf("len(ToSlice())=%d, expected %d", len(a), s.Len())
+	}
+	if other := NewSetFromSlice(nil, a); !other.Equal(s) {
+		t.Fatalf("expected ToSlice() to round-trip through NewSetFromSlice()")
+	}
+}
+
+func TestSortedSetToSlice(t *testing.T) {
+	s := NewSortedSetFromSlice(nil, []string{"c", "a", "d", "b"})
+	a := s.ToSlice()
+	if len(a) != s.Len() {
+		t.Fatalf("len(ToSlice())=%d, expected %d", len(a), s.Len())
+	}
+	for i, exp := range []string{"a", "b", "c", "d"} {
+		if a[i] != exp {
+			t.Fatalf("ToSlice()[%d]=%q, expected %q", i, a[i], exp)
+		}
+	}
+	if other := NewSortedSetFromSlice(nil, a); other.Len() != s.Len() {
+		t.Fatalf("expected ToSlice() to round-trip through NewSortedSetFromSlice()")
+	}
+}
+
+func TestSortedSetMinMax(t *testing.T) {
+	s := NewSortedSet[string](nil)
+	if v, ok := s.Min(); ok {
+		t.Fatalf("Min()=<%v,%v>, expected false for empty set", v, ok)
+	} else if v, ok := s.Max(); ok {
+		t.Fatalf("Max()=<%v,%v>, expected false for empty set", v, ok)
+	}
+
+	s = s.Put("m")
+	if v, ok := s.Min(); !ok || v != "m" {
+		t.Fatalf("Min()=<%v,%v>", v, ok)
+	} else if v, ok := s.Max(); !ok || v != "m" {
+		t.Fatalf("Max()=<%v,%v>", v, ok)
+	}
+
+	s = s.Put("z").Put("a").Put("q")
+	if v, ok := s.Min(); !ok || v != "a" {
+		t.Fatalf("Min()=<%v,%v>", v, ok)
+	} else if v, ok := s.Max(); !ok || v != "z" {
+		t.Fatalf("Max()=<%v,%v>", v, ok)
+	}
+}
+
+func TestSortedSetAlgebra(t *testing.T) {
+	rand := rand.New(rand.NewSource(0))
+	random := func(n, max int) []int {
+		vals := make([]int, n)
+		for i := range vals {
+			vals[i] = rand.Intn(max)
+		}
+		return vals
+	}
+
+	type op struct {
+		name     string
+		sorted   func(a, b SortedSet[int]) SortedSet[int]
+		unsorted func(a, b Set[int]) Set[int]
+	}
+	ops := []op{
+		{"Union", SortedSet[int].Union, Set[int].Union},
+		{"Intersection", SortedSet[int].Intersection, Set[int].Intersection},
+		{"Difference", SortedSet[int].Difference, Set[int].Difference},
+		{"SymmetricDifference", SortedSet[int].SymmetricDifference, Set[int].SymmetricDifference},
+	}
+
+	inputs := [][2][]int{
+		{nil, nil},
+		{random(100, 1000), nil},
+		{nil, random(100, 1000)},
+		{random(500, 1000), random(500, 1000)},
+		{random(1000, 100), random(10, 100)},
+		{random(100, 500), random(100, 500)},
+	}
+	for _, in := range inputs {
+		a, b := NewSortedSetFromSlice[int](nil, in[0]), NewSortedSetFromSlice[int](nil, in[1])
+		ua, ub := NewSetFromSlice[int](nil, in[0]), NewSetFromSlice[int](nil, in[1])
+		for _, op := range ops {
+			got, exp := op.sorted(a, b), op.unsorted(ua, ub)
+			if got.Len() != exp.Len() {
+				t.Fatalf("%s: Len()=%d, expected %d", op.name, got.Len(), exp.Len())
+			}
+			vals := got.ToSlice()
+			if !sort.IntsAreSorted(vals) {
+				t.Fatalf("%s: result not sorted: %v", op.name, vals)
+			}
+			for _, v := range vals {
+				if !exp.Has(v) {
+					t.Fatalf("%s: unexpected element %d", op.name, v)
+				}
+			}
+		}
+	}
+
+	t.Run("Comparer", func(t *testing.T) {
+		reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+		a := NewSortedSetFromSlice[int](reverse, []int{1, 2, 3})
+		b := NewSortedSetFromSlice[int](reverse, []int{2, 3, 4})
+		if got := fmt.Sprint(a.Union(b).ToSlice()); got != "[4 3 2 1]" {
+			t.Fatalf("Union()=%s", got)
+		} else if got := fmt.Sprint(a.SymmetricDifference(b).ToSlice()); got != "[4 1]" {
+			t.Fatalf("SymmetricDifference()=%s", got)
+		} else if a.Union(b).m.comparer != reverse {
+			t.Fatal("expected comparer to be retained")
+		}
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		a := NewSortedSetFromSlice[int](nil, []int{1, 2, 3})
+		b := NewSortedSetFromSlice[int](nil, []int{2, 5})
+		if a.Union(NewSortedSetFromSlice[int](nil, []int{1, 3})).m != a.m {
+			t.Fatal("expected Union() with a subset to return the receiver")
+		} else if a.Intersection(a.Put(4)).m != a.m {
+			t.Fatal("expected Intersection() with a superset to return the receiver")
+		} else if a.Difference(NewSortedSetFromSlice[int](nil, []int{4})).m != a.m {
+			t.Fatal("expected Difference() with a disjoint set to return the receiver")
+		} else if a.Difference(a).Len() != 0 {
+			t.Fatal("expected Difference() with itself to be empty")
+		} else if got := fmt.Sprint(a.Difference(b).ToSlice()); got != "[1 3]" {
+			t.Fatalf("Difference()=%s", got)
+		}
+	})
+}
+
+func TestSortedSetRankSelect(t *testing.T) {
+	vals := make([]int, 0, 500)
+	for i := 0; i < 500; i++ {
+		vals = append(vals, (i*7919)%1000)
+	}
+	s := NewSortedSetFromSlice[int](nil, vals)
+	sorted := append([]int(nil), vals...)
+	sort.Ints(sorted)
+
+	for k, exp := range sorted {
+		if v, ok := s.Select(k); !ok || v != exp {
+			t.Fatalf("Select(%d)=<%v,%v>, expected %d", k, v, ok, exp)
+		}
+	}
+	if _, ok := s.Select(-1); ok {
+		t.Fatal("expected Select(-1) to fail")
+	} else if _, ok := s.Select(len(sorted)); ok {
+		t.Fatal("expected Select(Len()) to fail")
+	}
+
+	for val := -1; val <= 1000; val++ {
+		exp := sort.SearchInts(sorted, val)
+		if got := s.Rank(val); got != exp {
+			t.Fatalf("Rank(%d)=%d, expected %d", val, got, exp)
+		}
+	}
+
+	if got := NewSortedSet[int](nil).Rank(5); got != 0 {
+		t.Fatalf("Rank() on empty set=%d, expected 0", got)
+	} else if _, ok := NewSortedSet[int](nil).Select(0); ok {
+		t.Fatal("expected Select() on empty set to fail")
+	}
+}
+
+func TestSortedSetRange(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		s := NewSortedSet[int](nil)
+		for i := 0; i < 100; i += 2 {
+			s = s.Put(i)
+		}
+
+		if r := s.Range(10, 20).ToSlice(); fmt.Sprint(r) != "[10 12 14 16 18]" {
+			t.Fatalf("Range(10, 20)=%v", r)
+		}
+		if r := s.Range(9, 15).ToSlice(); fmt.Sprint(r) != "[10 12 14]" {
+			t.Fatalf("Range(9, 15)=%v", r)
+		}
+		if r := s.Range(-10, 3).ToSlice(); fmt.Sprint(r) != "[0 2]" {
+			t.Fatalf("Range(-10, 3)=%v", r)
+		}
+		if r := s.Range(20, 10); r.Len() != 0 {
+			t.Fatalf("Range(20, 10).Len()=%d, expected 0", r.Len())
+		}
+		if r := s.Range(11, 12); r.Len() != 0 {
+			t.Fatalf("Range(11, 12).Len()=%d, expected 0", r.Len())
+		}
+		if s.Len() != 50 {
+			t.Fatalf("unexpected mutation of set")
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		s := NewSortedSetFromSlice(nil, []string{"apple", "banana", "cherry", "date", "fig"})
+		if r := s.Range("b", "d").ToSlice(); fmt.Sprint(r) != "[banana cherry]" {
+			t.Fatalf(`Range("b", "d")=%v`, r)
+		}
+		if r := s.Range("cherry", "fig").ToSlice(); fmt.Sprint(r) != "[cherry date]" {
+			t.Fatalf(`Range("cherry", "fig")=%v`, r)
+		}
+	})
+
+	t.Run("Comparer", func(t *testing.T) {
+		reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+		s := NewSortedSetFromSlice[int](reverse, []int{1, 2, 3, 4, 5})
+		if r := s.Range(4, 1).ToSlice(); fmt.Sprint(r) != "[4 3 2]" {
+			t.Fatalf("Range(4, 1)=%v", r)
+		}
+	})
+}
+
+func TestSetFilter(t *testing.T) {
+	const n = 10000
+	s := NewSet[int](nil)
+	for i := 0; i < n; i++ {
+		s = s.Set(i)
+	}
+
+	even := s.Filter(func(v int) bool { return v%2 == 0 })
+	if even.Len() != n/2 {
+		t.Fatalf("Len()=%d, expected %d", even.Len(), n/2)
+	}
+	for i := 0; i < n; i++ {
+		if even.Has(i) != (i%2 == 0) {
+			t.Fatalf("Has(%d)=%v", i, even.Has(i))
+		}
+	}
+	if s.Len() != n || !s.Has(1) {
+		t.Fatalf("unexpected mutation of original set")
+	}
+
+	if all := s.Filter(func(v int) bool { return true }); all.m != s.m {
+		t.Fatalf("expected original set when all elements are retained")
+	}
+	if none := s.Filter(func(v int) bool { return false }); none.Len() != 0 {
+		t.Fatalf("Len()=%d, expected 0", none.Len())
+	}
+}
+
+func TestSetWithBloom(t *testing.T) {
+	const n = 10000
+	b := NewSetBuilder[int](nil)
+	for i := 0; i < n; i++ {
+		b.Set(i * 2)
+	}
+	s := b.Build().WithBloom(n * 16)
+
+	// Add and delete through the set so the filter is carried over.
+	s = s.Set(-1).Set(-3).Delete(0)
+	s = s.SetMany(-5, -7).DeleteMany(2, 4)
+	if s.bloom == nil {
+		t.Fatal("expected filter to be carried over")
+	}
+
+	for i := 3; i < n; i++ {
+		if !s.MaybeHas(i * 2) {
+			t.Fatalf("MaybeHas(%d)=false, expected true", i*2)
+		}
+	}
+	if !s.MaybeHas(-1) || !s.MaybeHas(-3) || !s.MaybeHas(-5) || !s.MaybeHas(-7) {
+		t.Fatal("expected MaybeHas() to be true for added values")
+	} else if s.MaybeHas(0) || s.MaybeHas(2) || s.MaybeHas(4) {
+		t.Fatal("expected MaybeHas() to be false for deleted values")
+	}
+
+	// Most absent values are rejected by the filter alone.
+	var passed int
+	for i := 0; i < n; i++ {
+		if s.MaybeHas(i*2 + 1) {
+			t.Fatalf("MaybeHas(%d)=true, expected false", i*2+1)
+		} else if s.bloom.has(s.m.hasher.Hash(i*2 + 1)) {
+			passed++
+		}
+	}
+	if passed > n/10 {
+		t.Fatalf("bloom filter passed %d of %d absent values", passed, n)
+	}
+
+	if other := s.WithBloom(0); other.bloom != nil || other.Len() != s.Len() {
+		t.Fatal("expected WithBloom(0) to remove the filter")
+	} else if empty := NewSet[int](nil).WithBloom(64); empty.MaybeHas(1) || !empty.Set(1).MaybeHas(1) {
+		t.Fatal("unexpected MaybeHas() result for empty set")
+	}
+}
+
+func TestSetIterator_Prev(t *testing.T) {
+	b := NewSetBuilder[int](nil)
+	for i := 0; i < 1000; i++ {
+		b.Set(i)
+	}
+	s := b.Build()
+
+	var forward []int
+	for itr := s.Iterator(); !itr.Done(); {
+		v, _ := itr.Next()
+		forward = append(forward, v)
+	}
+
+	itr := s.Iterator()
+	itr.Last()
+	for i := len(forward) - 1; i >= 0; i-- {
+		if v, ok := itr.Prev(); !ok || v != forward[i] {
+			t.Fatalf("Prev()=<%v,%v>, expected %d", v, ok, forward[i])
+		}
+	}
+	if _, ok := itr.Prev(); ok || !itr.Done() {
+		t.Fatal("expected iterator to be done")
+	}
+
+	empty := NewSet[int](nil).Iterator()
+	if empty.Last(); !empty.Done() {
+		t.Fatal("expected iterator on empty set to be done")
+	}
+}
+
+func TestSetStream(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{1, 2, 3, 4, 5})
+
+	t.Run("All", func(t *testing.T) {
+		var got []int
+		for v := range s.Stream(context.Background()) {
+			got = append(got, v)
+		}
+		sort.Ints(got)
+		if fmt.Sprint(got) != "[1 2 3 4 5]" {
+			t.Fatalf("Stream()=%v", got)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		b := NewSetBuilder[int](nil)
+		for i := 0; i < 1000; i++ {
+			b.Set(i)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := b.Build().Stream(ctx)
+		for i := 0; i < 10; i++ {
+			<-ch
+		}
+		cancel()
+
+		// The channel is closed once the sending goroutine returns. At most one
+		// element may already be in flight when cancel is observed.
+		timeout := time.After(5 * time.Second)
+		for n := 0; ; n++ {
+			select {
+			case _, ok := <-ch:
+				if !ok {
+					return
+				} else if n > 1 {
+					t.Fatalf("received %d elements after cancel", n+1)
+				}
+			case <-timeout:
+				t.Fatal("timeout waiting for channel to close")
+			}
+		}
+	})
+}
+
+func TestSetCount(t *testing.T) {
+	b := NewSetBuilder[int](nil)
+	for i := 0; i < 10000; i++ {
+		b.Set(i)
+	}
+	s := b.Build()
+	if got, exp := s.Count(func(v int) bool { return v%2 == 0 }), 5000; got != exp {
+		t.Fatalf("Count()=%d, exp %d", got, exp)
+	} else if got := NewSet[int](nil).Count(func(v int) bool { return true }); got != 0 {
+		t.Fatalf("Count()=%d, exp 0", got)
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	t.Run("Set", func(t *testing.T) {
+		var s Set[string]
+		if s.Len() != 0 {
+			t.Fatalf("Len()=%d", s.Len())
+		} else if s.Has("a") || s.MaybeHas("a") {
+			t.Fatal("expected Has() to be false")
+		} else if itr := s.Iterator(); !itr.Done() {
+			t.Fatal("expected iterator to be done")
+		} else if _, ok := itr.Next(); ok {
+			t.Fatal("expected Next() to fail")
+		} else if len(s.ToSlice()) != 0 {
+			t.Fatalf("ToSlice()=%v", s.ToSlice())
+		} else if s.Count(func(string) bool { return true }) != 0 || s.Any(func(string) bool { return true }) {
+			t.Fatal("expected no elements")
+		} else if got := s.String(); got != "Set{}" {
+			t.Fatalf("String()=%q", got)
+		} else if !s.Equal(NewSet[string](nil)) || !s.IsSubset(NewSet[string](nil)) {
+			t.Fatal("expected zero value to equal an empty set")
+		} else if s.Delete("a").Len() != 0 {
+			t.Fatal("expected Delete() to return an empty set")
+		}
+		s.ForEach(func(string) bool {
+			t.Fatal("unexpected element")
+			return true
+		})
+
+		// Derived sets of a zero value are empty.
+		other := NewSet[string](nil).Set("a").Set("b")
+		if s.Filter(func(string) bool { return true }).Len() != 0 {
+			t.Fatal("expected Filter() to return an empty set")
+		} else if s.Intersection(other).Len() != 0 || other.Intersection(s).Len() != 0 {
+			t.Fatal("expected Intersection() to return an empty set")
+		} else if s.Difference(other).Len() != 0 {
+			t.Fatal("expected Difference() to return an empty set")
+		} else if !other.Difference(s).Equal(other) {
+			t.Fatal("expected Difference() of zero value to remove nothing")
+		} else if !s.Union(other).Equal(other) || !s.SymmetricDifference(other).Equal(other) {
+			t.Fatal("expected Union() and SymmetricDifference() to return other set")
+		}
+
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			s.Set("a")
+		}()
+		if r != `immutable.Set.Set: cannot add to zero-value set, use NewSet` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+
+		func() {
+			defer func() { r = recover().(string) }()
+			s.SetMany("a", "b")
+		}()
+		if r != `immutable.Set.SetMany: cannot add to zero-value set, use NewSet` {
+			t.Fatalf("unexpected panic: %q", r)
+		} else if s.SetMany().Len() != 0 {
+			t.Fatal("expected SetMany() with no values to return the zero value")
+		}
+	})
+
+	t.Run("SortedSet", func(t *testing.T) {
+		var s SortedSet[int]
+		if s.Len() != 0 {
+			t.Fatalf("Len()=%d", s.Len())
+		} else if s.Has(1) {
+			t.Fatal("expected Has() to be false")
+		} else if itr := s.Iterator(); !itr.Done() {
+			t.Fatal("expected iterator to be done")
+		} else if len(s.ToSlice()) != 0 {
+			t.Fatalf("ToSlice()=%v", s.ToSlice())
+		} else if s.Delete(1).Len() != 0 {
+			t.Fatal("expected Delete() to return an empty set")
+		} else if s.Range(0, 10).Len() != 0 {
+			t.Fatal("expected Range() to return an empty set")
+		}
+
+		other := NewSortedSet[int](nil).Put(1).Put(2)
+		if s.Intersection(other).Len() != 0 || other.Intersection(s).Len() != 0 {
+			t.Fatal("expected Intersection() to return an empty set")
+		} else if s.Difference(other).Len() != 0 {
+			t.Fatal("expected Difference() to return an empty set")
+		} else if got := other.Difference(s).ToSlice(); len(got) != 2 {
+			t.Fatalf("Difference()=%v, expected other set", got)
+		} else if s.Union(other).Len() != 2 || s.SymmetricDifference(other).Len() != 2 {
+			t.Fatal("expected Union() and SymmetricDifference() to return other set")
+		}
+
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			s.Put(1)
+		}()
+		if r != `immutable.SortedSet.Put: cannot add to zero-value set, use NewSortedSet` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+}
+
+func TestSetSample(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	rng := rand.New(rand.NewSource(0))
+
+	for _, n := range []int{1, 3, 9} {
+		sample := s.Sample(n, rng)
+		if len(sample) != n {
+			t.Fatalf("Sample(%d): len=%d", n, len(sample))
+		}
+		seen := make(map[int]bool)
+		for _, v := range sample {
+			if !s.Has(v) {
+				t.Fatalf("Sample(%d): unexpected element %d", n, v)
+			} else if seen[v] {
+				t.Fatalf("Sample(%d): duplicate element %d", n, v)
+			}
+			seen[v] = true
+		}
+	}
+
+	for _, n := range []int{10, 100} {
+		sample := s.Sample(n, nil)
+		sort.Ints(sample)
+		if got, exp := fmt.Sprint(sample), "[0 1 2 3 4 5 6 7 8 9]"; got != exp {
+			t.Fatalf("Sample(%d)=%s, expected %s", n, got, exp)
+		}
+	}
+
+	if got := s.Sample(0, rng); len(got) != 0 {
+		t.Fatalf("Sample(0)=%v", got)
+	} else if got := NewSet[int](nil).Sample(5, rng); len(got) != 0 {
+		t.Fatalf("Sample() on empty set=%v", got)
+	}
+
+	// Every element should be picked roughly equally often.
+	counts := make(map[int]int)
+	for i := 0; i < 10000; i++ {
+		for _, v := range s.Sample(2, rng) {
+			counts[v]++
+		}
+	}
+	for v := 0; v < 10; v++ {
+		if counts[v] < 1500 || counts[v] > 2500 {
+			t.Fatalf("element %d sampled %d times, expected about 2000", v, counts[v])
+		}
+	}
+}
+
+func TestSetAnyEvery(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{1, 3, 5, 6, 7})
+	isEven := func(v int) bool { return v%2 == 0 }
+	isPositive := func(v int) bool { return v > 0 }
+
+	if !s.Any(isEven) {
+		t.Fatal("Any(isEven)=false, expected true")
+	} else if s.Any(func(v int) bool { return v > 10 }) {
+		t.Fatal("Any(>10)=true, expected false")
+	} else if !s.Every(isPositive) {
+		t.Fatal("Every(isPositive)=false, expected true")
+	} else if s.Every(isEven) {
+		t.Fatal("Every(isEven)=true, expected false")
+	}
+
+	// Iteration stops at the first deciding element.
+	var calls int
+	s.Any(func(v int) bool { calls++; return true })
+	if calls != 1 {
+		t.Fatalf("Any() called pred %d times, expected 1", calls)
+	}
+	calls = 0
+	s.Every(func(v int) bool { calls++; return false })
+	if calls != 1 {
+		t.Fatalf("Every() called pred %d times, expected 1", calls)
+	}
+
+	empty := NewSet[int](nil)
+	if empty.Any(isPositive) {
+		t.Fatal("expected Any() on empty set to be false")
+	} else if !empty.Every(isEven) {
+		t.Fatal("expected Every() on empty set to be true")
+	}
+}
+
+func TestSetForEach(t *testing.T) {
+	s := NewSet[int](nil)
+	for i := 0; i < 100; i++ {
+		s = s.Set(i)
+	}
+
+	seen := make(map[int]bool)
+	s.ForEach(func(val int) bool {
+		seen[val] = true
+		return true
+	})
+	if len(seen) != 100 {
+		t.Fatalf("visited %d elements, expected 100", len(seen))
+	}
+
+	// Stop after the first even element.
+	var calls int
+	s.ForEach(func(val int) bool {
+		calls++
+		return val%2 != 0
+	})
+	if calls == 0 || calls == 100 {
+		t.Fatalf("unexpected call count: %d", calls)
+	}
+}
+
+func TestSetMap(t *testing.T) {
+	src := NewSet[int](nil)
+	for i := 0; i < 100; i++ {
+		src = src.Set(i)
+	}
+
+	strs := SetMap(src, nil, func(val int) string { return fmt.Sprint(val) })
+	if strs.Len() != 100 {
+		t.Fatalf("Len()=%d, expected 100", strs.Len())
+	}
+	for i := 0; i < 100; i++ {
+		if !strs.Has(fmt.Sprint(i)) {
+			t.Fatalf("expected %q in set", fmt.Sprint(i))
+		}
+	}
+
+	// Distinct elements collapse when they map to the same value.
+	mods := SetMap(src, nil, func(val int) int { return val % 3 })
+	if mods.Len() != 3 {
+		t.Fatalf("Len()=%d, expected 3", mods.Len())
+	} else if !mods.Has(0) || !mods.Has(1) || !mods.Has(2) {
+		t.Fatalf("unexpected elements: %v", mods)
+	}
+
+	if got := SetMap(NewSet[int](nil), nil, func(val int) int { return val }).Len(); got != 0 {
+		t.Fatalf("Len()=%d, expected 0", got)
+	}
+}
+
+func TestSetString(t *testing.T) {
+	if got, exp := NewSet[string](nil).String(), "Set{}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+
+	// Set order is unspecified so compare the sorted elements.
+	got := NewSetFromSlice[string](nil, []string{"c", "a", "b"}).String()
+	if !strings.HasPrefix(got, "Set{") || !strings.HasSuffix(got, "}") {
+		t.Fatalf("unexpected format: %q", got)
+	}
+	elems := strings.Split(strings.TrimSuffix(strings.TrimPrefix(got, "Set{"), "}"), ", ")
+	sort.Strings(elems)
+	if got, exp := strings.Join(elems, ","), "a,b,c"; got != exp {
+		t.Fatalf("unexpected elements: %q, expected %q", got, exp)
+	}
+
+	t.Run("Truncated", func(t *testing.T) {
+		s := NewSet[int](nil)
+		for i := 0; i < 150; i++ {
+			s = s.Set(i)
+		}
+		got := s.String()
+		if !strings.HasSuffix(got, ", ...}") {
+			t.Fatalf("expected truncated output: %q", got)
+		} else if n := strings.Count(got, ","); n != 100 {
+			t.Fatalf("unexpected element count: %d", n)
+		}
+	})
+}
+
+func TestSortedSetString(t *testing.T) {
+	if got, exp := NewSortedSet[string](nil).String(), "SortedSet{}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	} else if got, exp := NewSortedSetFromSlice[string](nil, []string{"c", "a", "b"}).String(), "SortedSet{a, b, c}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+
+	s := NewSortedSet[int](nil)
+	for i := 0; i < 150; i++ {
+		s = s.Put(i)
+	}
+	if got := s.String(); !strings.HasPrefix(got, "SortedSet{0, 1, 2,") || !strings.HasSuffix(got, ", 98, 99, ...}") {
+		t.Fatalf("unexpected truncated output: %q", got)
+	}
+}
+
+func TestSetSorted(t *testing.T) {
+	vals := make([]int, 1000)
+	for i := range vals {
+		vals[i] = (i * 7919) % 1000
+	}
+	s := NewSetFromSlice[int](nil, vals)
+
+	reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+	ss := s.Sorted(reverse)
+	if ss.Len() != s.Len() {
+		t.Fatalf("Sorted().Len()=%d, expected %d", ss.Len(), s.Len())
+	}
+	for i, v := range ss.ToSlice() {
+		if exp := 999 - i; v != exp {
+			t.Fatalf("Sorted()[%d]=%d, expected %d", i, v, exp)
+		}
+	}
+
+	other := ss.Unsorted(nil)
+	if other.Len() != s.Len() {
+		t.Fatalf("Unsorted().Len()=%d, expected %d", other.Len(), s.Len())
+	} else if !other.Equal(s) {
+		t.Fatal("expected round-tripped set to equal original")
+	}
+
+	if got := NewSet[int](nil).Sorted(nil); got.Len() != 0 {
+		t.Fatalf("Sorted().Len()=%d, expected 0", got.Len())
+	} else if got := NewSortedSet[int](nil).Unsorted(nil); got.Len() != 0 {
+		t.Fatalf("Unsorted().Len()=%d, expected 0", got.Len())
+	}
+}
+
+func TestSetClone(t *testing.T) {
+	s := NewSetFromSlice[int](nil, []int{1, 2})
+	if other := s.Clone(); other.m != s.m {
+		t.Fatal("expected Clone to return the same set")
+	}
+	ss := NewSortedSetFromSlice[int](nil, []int{1, 2})
+	if other := ss.Clone(); other.m != ss.m {
+		t.Fatal("expected Clone to return the same sorted set")
+	}
+}
+
+func TestSetClear(t *testing.T) {
+	h := &mockHasher[int]{
+		hash:  func(value int) uint32 { return hashUint64(uint64(value)) },
+		equal: func(a, b int) bool { return a == b },
+	}
+	s := NewSetFromSlice[int](h, []int{1, 2})
+	other := s.Clear()
+	if other.Len() != 0 {
+		t.Fatalf("Len()=%d, expected 0", other.Len())
+	} else if other.m.hasher != h {
+		t.Fatal("expected hasher to be retained")
+	} else if other.Clear().m != other.m {
+		t.Fatal("expected empty set to be returned")
+	} else if !other.Set(3).Has(3) {
+		t.Fatal("expected cleared set to accept inserts")
+	}
+
+	reverse := &mockComparer[int]{compare: func(a, b int) int { return defaultCompare(b, a) }}
+	ss := NewSortedSetFromSlice[int](reverse, []int{1, 2}).Clear()
+	if ss.Len() != 0 {
+		t.Fatalf("Len()=%d, expected 0", ss.Len())
+	} else if got, exp := ss.Put(1).Put(2).String(), "SortedSet{2, 1}"; got != exp {
+		t.Fatalf("String()=%q, expected %q", got, exp)
+	}
+}
@@ -0,0 +1,39 @@
+package immutable
+
+// Stack represents an immutable last-in, first-out collection. It is a thin
+// adapter over List: elements are pushed onto and popped from the end of the
+// list so each operation is a path copy that shares the rest of the list.
+type Stack[T any] struct {
+	l *List[T]
+}
+
+// NewStack returns a new stack containing values. The last value is on top.
+func NewStack[T any](values ...T) Stack[T] {
+	return Stack[T]{l: NewList(values...)}
+}
+
+// Len returns the number of elements in the stack.
+func (s Stack[T]) Len() int {
+	return s.l.Len()
+}
+
+// Push returns a new stack with value on top.
+func (s Stack[T]) Push(value T) Stack[T] {
+	return Stack[T]{l: s.l.Append(value)}
+}
+
+// Pop returns the top element and a new stack without it. Returns false and
+// the original stack if the stack is empty.
+func (s Stack[T]) Pop() (value T, other Stack[T], ok bool) {
+	n := s.l.Len()
+	if n == 0 {
+		return value, s, false
+	}
+	return s.l.Get(n - 1), Stack[T]{l: s.l.Slice(0, n-1)}, true
+}
+
+// Peek returns the top element without removing it. Returns false if the
+// stack is empty.
+func (s Stack[T]) Peek() (value T, ok bool) {
+	return s.l.Last()
+}
@@ -0,0 +1,72 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	const n = 100
+	s := NewStack[int]()
+	snapshots := make([]Stack[int], 0, n)
+	for i := 0; i < n; i++ {
+		s = s.Push(i)
+		snapshots = append(snapshots, s)
+	}
+	if s.Len() != n {
+		t.Fatalf("Len()=%d, expected %d", s.Len(), n)
+	}
+
+	// Elements are popped in reverse order of pushing.
+	other := s
+	for exp := n - 1; exp >= 0; exp-- {
+		if v, ok := other.Peek(); !ok || v != exp {
+			t.Fatalf("Peek()=<%v,%v>, expected %d", v, ok, exp)
+		}
+		v, next, ok := other.Pop()
+		if !ok || v != exp {
+			t.Fatalf("Pop()=<%v,%v>, expected %d", v, ok, exp)
+		} else if next.Len() != exp {
+			t.Fatalf("Len()=%d after Pop(), expected %d", next.Len(), exp)
+		}
+		other = next
+	}
+
+	// Older snapshots are unaffected.
+	for i, snap := range snapshots {
+		if v, ok := snap.Peek(); !ok || v != i || snap.Len() != i+1 {
+			t.Fatalf("snapshot %d: Peek()=<%v,%v>, Len()=%d", i, v, ok, snap.Len())
+		}
+	}
+
+	// Pushing onto a snapshot only copies the path to the top so the first
+	// leaf is shared.
+	firstLeaf := func(s Stack[int]) listNode[int] {
+		node := s.l.root
+		for {
+			branch, ok := node.(*listBranchNode[int])
+			if !ok {
+				return node
+			}
+			node = branch.children[0]
+		}
+	}
+	if firstLeaf(snapshots[n-1]) != firstLeaf(snapshots[n-1].Push(-1)) {
+		t.Fatal("expected pushed stack to share leading nodes")
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		s := NewStack[int]()
+		if _, ok := s.Peek(); ok {
+			t.Fatal("expected Peek() on empty stack to fail")
+		} else if _, other, ok := s.Pop(); ok || other.l != s.l {
+			t.Fatal("expected Pop() on empty stack to return the same stack")
+		}
+	})
+
+	t.Run("NewStack", func(t *testing.T) {
+		s := NewStack(1, 2, 3)
+		if v, ok := s.Peek(); !ok || v != 3 || s.Len() != 3 {
+			t.Fatalf("Peek()=<%v,%v>, Len()=%d", v, ok, s.Len())
+		}
+	})
+}